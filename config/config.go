@@ -22,6 +22,7 @@ import (
 	"github.com/coreos/ignition/config/types"
 	"github.com/coreos/ignition/config/v1"
 	"github.com/coreos/ignition/config/v2_0"
+	"github.com/coreos/ignition/config/v2_1"
 	"github.com/coreos/ignition/config/validate"
 	astjson "github.com/coreos/ignition/config/validate/astjson"
 	"github.com/coreos/ignition/config/validate/report"
@@ -56,6 +57,8 @@ func Parse(rawConfig []byte) (types.Config, report.Report, error) {
 		return config, report.ReportFromError(ErrDeprecated, report.EntryDeprecated), nil
 	case semver.Version{Major: 2, Minor: 0}:
 		return ParseFromV2_0(rawConfig)
+	case semver.Version{Major: 2, Minor: 1}:
+		return ParseFromV2_1(rawConfig)
 	default:
 		return ParseFromLatest(rawConfig)
 	}
@@ -156,6 +159,15 @@ func ParseFromV2_0(rawConfig []byte) (types.Config, report.Report, error) {
 	return TranslateFromV2_0(cfg), report, err
 }
 
+func ParseFromV2_1(rawConfig []byte) (types.Config, report.Report, error) {
+	cfg, report, err := v2_1.Parse(rawConfig)
+	if err != nil {
+		return types.Config{}, report, err
+	}
+
+	return TranslateFromV2_1(cfg), report, err
+}
+
 func version(rawConfig []byte) (semver.Version, error) {
 	var composite struct {
 		Version  *int `json:"ignitionVersion"`
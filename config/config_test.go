@@ -22,6 +22,8 @@ import (
 	"github.com/coreos/ignition/config/types"
 	v1 "github.com/coreos/ignition/config/v1"
 	v2_0 "github.com/coreos/ignition/config/v2_0/types"
+	"github.com/coreos/ignition/config/validate"
+	"github.com/coreos/ignition/config/validate/report"
 )
 
 func TestParse(t *testing.T) {
@@ -51,11 +53,15 @@ func TestParse(t *testing.T) {
 			out: out{config: types.Config{Ignition: types.Ignition{Version: types.MaxVersion.String()}}},
 		},
 		{
-			in:  in{config: []byte(`{"ignition": {"version": "2.1.0-experimental"}}`)},
+			in:  in{config: []byte(`{"ignition": {"version": "2.1.0"}}`)},
 			out: out{config: types.Config{Ignition: types.Ignition{Version: types.MaxVersion.String()}}},
 		},
 		{
-			in:  in{config: []byte(`{"ignition": {"version": "2.1.0"}}`)},
+			in:  in{config: []byte(`{"ignition": {"version": "2.2.0-experimental"}}`)},
+			out: out{config: types.Config{Ignition: types.Ignition{Version: types.MaxVersion.String()}}},
+		},
+		{
+			in:  in{config: []byte(`{"ignition": {"version": "2.2.0"}}`)},
 			out: out{err: ErrInvalid},
 		},
 		{
@@ -112,3 +118,65 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestParseReportPaths(t *testing.T) {
+	type in struct {
+		config []byte
+	}
+	type out struct {
+		entries []report.Entry
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{config: []byte(`{"ignition": {"version": "2.2.0-experimental"}}`)},
+			out: out{},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "2.2.0-experimental"}, "storage": {"disks": [{"device": "/dev/sda", "partitions": [{"number": 1, "start": 2048}, {"number": 1, "start": 4096}]}]}}`)},
+			out: out{entries: []report.Entry{
+				{Code: types.CodePartitionNumbersCollide, Path: "/storage/disks/0/partitions"},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "2.2.0-experimental"}, "systemd": {"units": [{"name": "foo.service", "a/b": true}]}}`)},
+			out: out{entries: []report.Entry{
+				{Code: validate.CodeUnrecognizedKey, Path: "/systemd/units/0/a~1b"},
+			}},
+		},
+		{
+			in: in{config: []byte(`{
+  "ignition": {"version": "2.2.0-experimental"},
+  "systemd": {"units": [
+    {"name": "foo.service", "enable": true}
+  ]}
+}`)},
+			out: out{entries: []report.Entry{
+				{Code: types.CodeUnitEnabledUndefined, Path: "/systemd/units/0/enable", Line: 4, Column: 43},
+			}},
+		},
+		{
+			in: in{config: []byte(`{"ignition": {"version": "2.2.0-experimental"}, "storage": {"files": [{"filesystem": "root", "path": "/foo"}], "directories": [{"filesystem": "root", "path": "/foo"}]}}`)},
+			out: out{entries: []report.Entry{
+				{Code: types.CodeNodeTypeConflict, Path: "/storage/directories/0", RelatedPath: "/storage/files/0"},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		_, r, _ := Parse(test.in.config)
+		if len(r.Entries) != len(test.out.entries) {
+			t.Errorf("#%d: bad report: want %d entries, got %+v", i, len(test.out.entries), r)
+			continue
+		}
+		for j, e := range test.out.entries {
+			if r.Entries[j].Code != e.Code || r.Entries[j].Path != e.Path || r.Entries[j].RelatedPath != e.RelatedPath ||
+				(e.Line != 0 && (r.Entries[j].Line != e.Line || r.Entries[j].Column != e.Column)) {
+				t.Errorf("#%d: bad entry %d: want code %q and path %q, got %+v", i, j, e.Code, e.Path, r.Entries[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,380 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+
+	// diffContext is the number of unchanged lines shown around each change
+	// of decoded file contents.
+	diffContext = 3
+
+	// maxDiffCells limits the size of the table diffLines builds, which has
+	// an entry for every pair of old and new lines. Larger contents are only
+	// reported as differing.
+	maxDiffCells = 1 << 22
+
+	// diffTooLarge stands in for the Diff of contents beyond maxDiffCells.
+	diffTooLarge = "contents differ (too large to diff)\n"
+)
+
+// Change is a single difference between two configs. Path locates the
+// difference, with list entries which have a natural identity (see Merge)
+// addressed by that identity instead of their index, e.g.
+// "storage.files[root:/etc/motd].mode". Old and New hold the differing values
+// (either of which is nil for added and removed entries). If the difference
+// involves file contents given as data URLs, Diff holds a line diff of the
+// decoded contents, or a note that they differ if they are too large to diff.
+type Change struct {
+	Kind ChangeKind  `json:"kind"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+	Diff string      `json:"diff,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeChanged:
+		if c.Diff != "" {
+			return fmt.Sprintf("changed %s:\n%s", c.Path, strings.TrimSuffix(c.Diff, "\n"))
+		}
+		return fmt.Sprintf("changed %s: %s -> %s", c.Path, formatValue(c.Old), formatValue(c.New))
+	default:
+		if c.Diff != "" {
+			return fmt.Sprintf("%s %s:\n%s", c.Kind, c.Path, strings.TrimSuffix(c.Diff, "\n"))
+		}
+		return fmt.Sprintf("%s %s", c.Kind, c.Path)
+	}
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// Diff returns the semantic differences between oldConfig and newConfig.
+// Unlike a textual diff of the configs, reordering list entries which have a
+// natural identity (e.g. the path and filesystem of a file or the name of a
+// unit) is not a change, and entries are compared with the entry of the same
+// identity in the other config.
+func Diff(oldConfig, newConfig types.Config) []Change {
+	return diffValue(reflect.ValueOf(oldConfig), reflect.ValueOf(newConfig), "")
+}
+
+// diffValue is an internal helper function to Diff. Given two values of the
+// same type, it returns the changes from vOld to vNew.
+func diffValue(vOld, vNew reflect.Value, path string) []Change {
+	if vOld.Type() == reflect.TypeOf(types.FileContents{}) {
+		return diffContents(vOld.Interface().(types.FileContents), vNew.Interface().(types.FileContents), path)
+	}
+
+	switch vOld.Kind() {
+	case reflect.Struct:
+		return diffStruct(vOld, vNew, path)
+	case reflect.Slice:
+		if _, ok := mergeKeys[vOld.Type().Elem()]; ok {
+			return diffKeyedSlice(vOld, vNew, path)
+		}
+	case reflect.Ptr:
+		if !vOld.IsNil() && !vNew.IsNil() && vOld.Elem().Kind() == reflect.Struct {
+			return diffStruct(vOld.Elem(), vNew.Elem(), path)
+		}
+	}
+
+	if reflect.DeepEqual(vOld.Interface(), vNew.Interface()) {
+		return nil
+	}
+	return []Change{{
+		Kind: ChangeChanged,
+		Path: path,
+		Old:  diffInterface(vOld),
+		New:  diffInterface(vNew),
+	}}
+}
+
+// diffInterface returns the value of v to report in a Change, with unset
+// values reported as nil.
+func diffInterface(v reflect.Value) interface{} {
+	if isZero(v) {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		return v.Elem().Interface()
+	}
+	return v.Interface()
+}
+
+// diffStruct is an internal helper function to Diff. It diffs every field of
+// the struct, using the JSON names of the fields in the path. Embedded structs
+// are diffed as if their fields were part of the parent.
+func diffStruct(vOld, vNew reflect.Value, path string) []Change {
+	var changes []Change
+	t := vOld.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = joinPath(path, strings.Split(field.Tag.Get("json"), ",")[0])
+		}
+		changes = append(changes, diffValue(vOld.Field(i), vNew.Field(i), fieldPath)...)
+	}
+	return changes
+}
+
+// diffKeyedSlice is an internal helper function to Diff. Entries are paired
+// with the entry of the same identity in the other slice, regardless of their
+// position. Unpaired entries are reported as removed or added.
+func diffKeyedSlice(vOld, vNew reflect.Value, path string) []Change {
+	key := mergeKeys[vOld.Type().Elem()]
+	paired := make([]bool, vOld.Len())
+	pairs := make([]int, vNew.Len())
+
+	for i := 0; i < vNew.Len(); i++ {
+		pairs[i] = -1
+		k := key(vNew.Index(i).Interface())
+		for j := 0; j < vOld.Len(); j++ {
+			if !paired[j] && key(vOld.Index(j).Interface()) == k {
+				paired[j] = true
+				pairs[i] = j
+				break
+			}
+		}
+	}
+
+	var changes []Change
+	for j := 0; j < vOld.Len(); j++ {
+		if !paired[j] {
+			changes = append(changes, entryChange(ChangeRemoved, vOld.Index(j), entryPath(path, key, vOld.Index(j))))
+		}
+	}
+	for i := 0; i < vNew.Len(); i++ {
+		entry := entryPath(path, key, vNew.Index(i))
+		if pairs[i] < 0 {
+			changes = append(changes, entryChange(ChangeAdded, vNew.Index(i), entry))
+		} else {
+			changes = append(changes, diffValue(vOld.Index(pairs[i]), vNew.Index(i), entry)...)
+		}
+	}
+	return changes
+}
+
+// entryChange returns the Change for an added or removed list entry. The
+// contents of files are diffed against nothing so that they show up decoded.
+func entryChange(kind ChangeKind, v reflect.Value, path string) Change {
+	c := Change{Kind: kind, Path: path}
+	if kind == ChangeAdded {
+		c.New = v.Interface()
+	} else {
+		c.Old = v.Interface()
+	}
+
+	if f, ok := v.Interface().(types.File); ok {
+		if text, ok := decodeContents(f.Contents.Source); ok {
+			if kind == ChangeAdded {
+				c.Diff = diffLinesLimited("", text)
+			} else {
+				c.Diff = diffLinesLimited(text, "")
+			}
+		}
+	}
+	return c
+}
+
+func entryPath(path string, key func(interface{}) interface{}, v reflect.Value) string {
+	k := key(v.Interface())
+	if node, ok := k.([2]string); ok {
+		return fmt.Sprintf("%s[%s:%s]", path, node[0], node[1])
+	}
+	return fmt.Sprintf("%s[%v]", path, k)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// diffContents is an internal helper function to Diff. If the contents of
+// both files are given as data URLs, the decoded contents are diffed instead
+// of the URLs themselves.
+func diffContents(old, new types.FileContents, path string) []Change {
+	oldText, oldOk := decodeContents(old.Source)
+	newText, newOk := decodeContents(new.Source)
+	if !oldOk || !newOk || old.Compression != "" || new.Compression != "" {
+		return diffStruct(reflect.ValueOf(old), reflect.ValueOf(new), path)
+	}
+
+	old.Source, new.Source = "", ""
+	changes := diffStruct(reflect.ValueOf(old), reflect.ValueOf(new), path)
+	if oldText != newText {
+		changes = append(changes, Change{
+			Kind: ChangeChanged,
+			Path: joinPath(path, "source"),
+			Diff: diffLinesLimited(oldText, newText),
+		})
+	}
+	return changes
+}
+
+// decodeContents returns the contents of a data URL and whether source was
+// one. The empty source stands for an empty file.
+func decodeContents(source string) (string, bool) {
+	if source == "" {
+		return "", true
+	}
+	if !strings.HasPrefix(source, "data:") {
+		return "", false
+	}
+	u, err := dataurl.DecodeString(source)
+	if err != nil {
+		return "", false
+	}
+	return string(u.Data), true
+}
+
+// diffLinesLimited returns diffLines(old, new), unless the contents are too
+// large to be diffed, in which case only diffTooLarge is returned.
+func diffLinesLimited(old, new string) string {
+	if (len(splitLines(old))+1)*(len(splitLines(new))+1) > maxDiffCells {
+		return diffTooLarge
+	}
+	return diffLines(old, new)
+}
+
+// diffLines returns a unified diff of the lines of old and new, with
+// diffContext lines of context around each change.
+func diffLines(old, new string) string {
+	a := splitLines(old)
+	b := splitLines(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+		a, b int // line numbers (0-based) in old and new before this line
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i], i, j})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			lines = append(lines, line{'+', b[j], i, j})
+			j++
+		default:
+			lines = append(lines, line{'-', a[i], i, j})
+			i++
+		}
+	}
+
+	var buf bytes.Buffer
+	for start := 0; start < len(lines); {
+		if lines[start].op == ' ' {
+			start++
+			continue
+		}
+
+		// Grow the hunk until it is followed by more than twice the context
+		// of unchanged lines (or the end).
+		first := start - diffContext
+		if first < 0 {
+			first = 0
+		}
+		end := start
+		for k := start; k < len(lines) && k <= end+2*diffContext; k++ {
+			if lines[k].op != ' ' {
+				end = k
+			}
+		}
+		last := end + diffContext
+		if last >= len(lines) {
+			last = len(lines) - 1
+		}
+
+		oldCount, newCount := 0, 0
+		for _, l := range lines[first : last+1] {
+			if l.op != '+' {
+				oldCount++
+			}
+			if l.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(lines[first].a, oldCount), hunkRange(lines[first].b, newCount))
+		for _, l := range lines[first : last+1] {
+			fmt.Fprintf(&buf, "%c%s\n", l.op, l.text)
+		}
+		start = last + 1
+	}
+	return buf.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,215 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestDiff(t *testing.T) {
+	type in struct {
+		oldConfig types.Config
+		newConfig types.Config
+	}
+	type out struct {
+		changes []Change
+	}
+
+	motd := types.File{
+		Node:          types.Node{Filesystem: "root", Path: "/etc/motd"},
+		FileEmbedded1: types.FileEmbedded1{Mode: 420, Contents: types.FileContents{Source: "data:,hello%0Aworld%0A"}},
+	}
+	hosts := types.File{
+		Node:          types.Node{Filesystem: "root", Path: "/etc/hosts"},
+		FileEmbedded1: types.FileEmbedded1{Mode: 420, Contents: types.FileContents{Source: "http://example.com/hosts"}},
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		// empty
+		{
+			in:  in{},
+			out: out{},
+		},
+
+		// reordered entries are not changes
+		{
+			in: in{
+				oldConfig: types.Config{
+					Storage: types.Storage{Files: []types.File{motd, hosts}},
+					Systemd: types.Systemd{Units: []types.Unit{{Name: "a.service"}, {Name: "b.service"}}},
+				},
+				newConfig: types.Config{
+					Storage: types.Storage{Files: []types.File{hosts, motd}},
+					Systemd: types.Systemd{Units: []types.Unit{{Name: "b.service"}, {Name: "a.service"}}},
+				},
+			},
+			out: out{},
+		},
+
+		// added, removed and changed entries
+		{
+			in: in{
+				oldConfig: types.Config{
+					Ignition: types.Ignition{Version: "2.1.0"},
+					Storage:  types.Storage{Disks: []types.Disk{{Device: "/dev/sda", WipeTable: true}}},
+					Systemd:  types.Systemd{Units: []types.Unit{{Name: "a.service", Enable: true}}},
+					Passwd:   types.Passwd{Users: []types.PasswdUser{{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"key1"}}}},
+				},
+				newConfig: types.Config{
+					Ignition: types.Ignition{Version: "2.2.0-experimental"},
+					Storage:  types.Storage{Disks: []types.Disk{{Device: "/dev/sdb", WipeTable: true}}},
+					Systemd:  types.Systemd{Units: []types.Unit{{Name: "a.service", Mask: true}}},
+					Passwd:   types.Passwd{Users: []types.PasswdUser{{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"key1", "key2"}}}},
+				},
+			},
+			out: out{changes: []Change{
+				{Kind: ChangeChanged, Path: "ignition.version", Old: "2.1.0", New: "2.2.0-experimental"},
+				{
+					Kind: ChangeChanged,
+					Path: "passwd.users[core].sshAuthorizedKeys",
+					Old:  []types.SSHAuthorizedKey{"key1"},
+					New:  []types.SSHAuthorizedKey{"key1", "key2"},
+				},
+				{Kind: ChangeRemoved, Path: "storage.disks[/dev/sda]", Old: types.Disk{Device: "/dev/sda", WipeTable: true}},
+				{Kind: ChangeAdded, Path: "storage.disks[/dev/sdb]", New: types.Disk{Device: "/dev/sdb", WipeTable: true}},
+				{Kind: ChangeChanged, Path: "systemd.units[a.service].enable", Old: true},
+				{Kind: ChangeChanged, Path: "systemd.units[a.service].mask", New: true},
+			}},
+		},
+
+		// data urls are decoded and diffed
+		{
+			in: in{
+				oldConfig: types.Config{Storage: types.Storage{Files: []types.File{motd}}},
+				newConfig: types.Config{Storage: types.Storage{Files: []types.File{{
+					Node:          motd.Node,
+					FileEmbedded1: types.FileEmbedded1{Mode: 384, Contents: types.FileContents{Source: "data:,hello%0Athere%0Aworld%0A"}},
+				}}}},
+			},
+			out: out{changes: []Change{
+				{Kind: ChangeChanged, Path: "storage.files[root:/etc/motd].contents.source", Diff: "@@ -1,2 +1,3 @@\n hello\n+there\n world\n"},
+				{Kind: ChangeChanged, Path: "storage.files[root:/etc/motd].mode", Old: 420, New: 384},
+			}},
+		},
+		{
+			in: in{
+				newConfig: types.Config{Storage: types.Storage{Files: []types.File{motd}}},
+			},
+			out: out{changes: []Change{
+				{Kind: ChangeAdded, Path: "storage.files[root:/etc/motd]", New: motd, Diff: "@@ -0,0 +1,2 @@\n+hello\n+world\n"},
+			}},
+		},
+
+		// other sources are compared as urls
+		{
+			in: in{
+				oldConfig: types.Config{Storage: types.Storage{Files: []types.File{motd}}},
+				newConfig: types.Config{Storage: types.Storage{Files: []types.File{{
+					Node:          motd.Node,
+					FileEmbedded1: types.FileEmbedded1{Mode: 420, Contents: types.FileContents{Source: "http://example.com/motd"}},
+				}}}},
+			},
+			out: out{changes: []Change{
+				{Kind: ChangeChanged, Path: "storage.files[root:/etc/motd].contents.source", Old: "data:,hello%0Aworld%0A", New: "http://example.com/motd"},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		changes := Diff(test.in.oldConfig, test.in.newConfig)
+		if !reflect.DeepEqual(test.out.changes, changes) {
+			t.Errorf("#%d: bad changes: want %+v, got %+v", i, test.out.changes, changes)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	type in struct {
+		old string
+		new string
+	}
+	type out struct {
+		diff string
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{old: "a\nb\nc\n", new: "a\nb\nc\n"},
+			out: out{diff: ""},
+		},
+		{
+			in:  in{old: "a\nb\nc\n", new: "a\nc\nd\n"},
+			out: out{diff: "@@ -1,3 +1,3 @@\n a\n-b\n c\n+d\n"},
+		},
+		{
+			in:  in{old: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", new: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n"},
+			out: out{diff: "@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n"},
+		},
+	}
+
+	for i, test := range tests {
+		diff := diffLines(test.in.old, test.in.new)
+		if diff != test.out.diff {
+			t.Errorf("#%d: bad diff: want %q, got %q", i, test.out.diff, diff)
+		}
+	}
+}
+
+func TestDiffLinesLimited(t *testing.T) {
+	type in struct {
+		old string
+		new string
+	}
+	type out struct {
+		diff string
+	}
+
+	large := strings.Repeat("a\n", 4096)
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{old: "a\n", new: "b\n"},
+			out: out{diff: "@@ -1,1 +1,1 @@\n+b\n-a\n"},
+		},
+		{
+			in:  in{old: "", new: large},
+			out: out{diff: "@@ -0,0 +1,4096 @@\n" + strings.Repeat("+a\n", 4096)},
+		},
+		{
+			in:  in{old: large, new: large + "b\n"},
+			out: out{diff: diffTooLarge},
+		},
+	}
+
+	for i, test := range tests {
+		diff := diffLinesLimited(test.in.old, test.in.new)
+		if diff != test.out.diff {
+			t.Errorf("#%d: bad diff: want %q, got %q", i, test.out.diff, diff)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+// mergeKeys maps the types of the list entries which have a natural identity
+// to a function returning that identity. Entries of a new config which share
+// an identity with an entry of the old config are merged into it instead of
+// being appended.
+var mergeKeys = map[reflect.Type]func(interface{}) interface{}{
+	reflect.TypeOf(types.Disk{}):         func(v interface{}) interface{} { return v.(types.Disk).Device },
+	reflect.TypeOf(types.Filesystem{}):   func(v interface{}) interface{} { return v.(types.Filesystem).Name },
+	reflect.TypeOf(types.File{}):         func(v interface{}) interface{} { return nodeKey(v.(types.File).Node) },
+	reflect.TypeOf(types.Directory{}):    func(v interface{}) interface{} { return nodeKey(v.(types.Directory).Node) },
+	reflect.TypeOf(types.Link{}):         func(v interface{}) interface{} { return nodeKey(v.(types.Link).Node) },
+	reflect.TypeOf(types.Removal{}):      func(v interface{}) interface{} { return removalKey(v.(types.Removal)) },
+	reflect.TypeOf(types.Unit{}):         func(v interface{}) interface{} { return v.(types.Unit).Name },
+	reflect.TypeOf(types.Dropin{}):       func(v interface{}) interface{} { return v.(types.Dropin).Name },
+	reflect.TypeOf(types.Networkdunit{}): func(v interface{}) interface{} { return v.(types.Networkdunit).Name },
+	reflect.TypeOf(types.PasswdUser{}):   func(v interface{}) interface{} { return v.(types.PasswdUser).Name },
+	reflect.TypeOf(types.PasswdGroup{}):  func(v interface{}) interface{} { return v.(types.PasswdGroup).Name },
+}
+
+// mergeAtomic lists the types which are always taken as a whole from the
+// newer config. Merging the contents of a file field by field could, for
+// example, combine a new source with the verification hash of the old one.
+var mergeAtomic = map[reflect.Type]bool{
+	reflect.TypeOf(types.FileContents{}): true,
+}
+
+func nodeKey(n types.Node) interface{} {
+	return [2]string{n.Filesystem, n.Path}
+}
+
+func removalKey(r types.Removal) interface{} {
+	return [2]string{r.Filesystem, r.Path}
+}
+
+// Merge merges newConfig into oldConfig and returns the result. Unlike Append,
+// list entries with a natural identity (e.g. the path and filesystem of a
+// file or the name of a unit) which are present in both configs are merged
+// rather than duplicated. Matching entries are merged field by field: set
+// values in newConfig override those in oldConfig, unset (zero) values leave
+// the old value in place, and nested lists are merged recursively. Since false
+// is the zero value, a boolean field (e.g. the "enable" of a unit) can't be
+// overridden from true to false. All other list entries are appended. As with
+// Append, "ignition.version" uses the old value and "ignition.config" uses the
+// new value.
+func Merge(oldConfig, newConfig types.Config) types.Config {
+	vOld := reflect.ValueOf(oldConfig)
+	vNew := reflect.ValueOf(newConfig)
+
+	vResult := mergeStruct(vOld, vNew)
+
+	return vResult.Interface().(types.Config)
+}
+
+// mergeStruct is an internal helper function to Merge. Given two values of
+// structures (assumed to be the same type), it merges every field of the
+// struct using mergeValue.
+func mergeStruct(vOld, vNew reflect.Value) reflect.Value {
+	tOld := vOld.Type()
+	vRes := reflect.New(tOld)
+
+	for i := 0; i < tOld.NumField(); i++ {
+		vfOld := vOld.Field(i)
+		vfNew := vNew.Field(i)
+		vfRes := vRes.Elem().Field(i)
+
+		switch tOld.Field(i).Name {
+		case "Version":
+			vfRes.Set(vfOld)
+			continue
+		case "Config":
+			vfRes.Set(vfNew)
+			continue
+		}
+
+		vfRes.Set(mergeValue(vfOld, vfNew))
+	}
+
+	return vRes.Elem()
+}
+
+// mergeValue is an internal helper function to Merge. Given two values of the
+// same type, it returns the result of merging vNew into vOld.
+func mergeValue(vOld, vNew reflect.Value) reflect.Value {
+	if mergeAtomic[vOld.Type()] {
+		if isZero(vNew) {
+			return vOld
+		}
+		return vNew
+	}
+
+	switch vOld.Kind() {
+	case reflect.Struct:
+		return mergeStruct(vOld, vNew)
+	case reflect.Slice:
+		return mergeSlice(vOld, vNew)
+	case reflect.Ptr:
+		if vNew.IsNil() {
+			return vOld
+		}
+		if vOld.IsNil() || vOld.Elem().Kind() != reflect.Struct {
+			return vNew
+		}
+		vRes := reflect.New(vOld.Elem().Type())
+		vRes.Elem().Set(mergeStruct(vOld.Elem(), vNew.Elem()))
+		return vRes
+	default:
+		if isZero(vNew) {
+			return vOld
+		}
+		return vNew
+	}
+}
+
+// mergeSlice is an internal helper function to Merge. Entries of vNew which
+// have the same identity as an entry of vOld are merged into that entry, and
+// all other entries are appended. Slices of types without an identity are
+// simply appended.
+func mergeSlice(vOld, vNew reflect.Value) reflect.Value {
+	key, ok := mergeKeys[vOld.Type().Elem()]
+	if !ok {
+		return reflect.AppendSlice(vOld, vNew)
+	}
+	if vNew.Len() == 0 {
+		return vOld
+	}
+
+	// Copy the old entries so that merging doesn't modify oldConfig. The new
+	// entries are merged even if there are no old ones, so that duplicates
+	// within newConfig are merged regardless of the contents of oldConfig.
+	vRes := reflect.AppendSlice(reflect.MakeSlice(vOld.Type(), 0, vOld.Len()+vNew.Len()), vOld)
+	for i := 0; i < vNew.Len(); i++ {
+		vEntry := vNew.Index(i)
+		k := key(vEntry.Interface())
+
+		merged := false
+		for j := 0; j < vRes.Len(); j++ {
+			if key(vRes.Index(j).Interface()) == k {
+				vRes.Index(j).Set(mergeValue(vRes.Index(j), vEntry))
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			vRes = reflect.Append(vRes, vEntry)
+		}
+	}
+
+	return vRes
+}
+
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,301 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestMerge(t *testing.T) {
+	type in struct {
+		oldConfig types.Config
+		newConfig types.Config
+	}
+	type out struct {
+		config types.Config
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		// empty
+		{
+			in: in{
+				oldConfig: types.Config{},
+				newConfig: types.Config{},
+			},
+			out: out{config: types.Config{}},
+		},
+
+		// merge tags
+		{
+			in: in{
+				oldConfig: types.Config{
+					Ignition: types.Ignition{
+						Version: semver.Version{Major: 2}.String(),
+					},
+				},
+				newConfig: types.Config{
+					Ignition: types.Ignition{
+						Version: semver.Version{Major: 2, Minor: 1}.String(),
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{
+					Version: semver.Version{Major: 2}.String(),
+				},
+			}},
+		},
+
+		// entries without a shared identity are appended
+		{
+			in: in{
+				oldConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{{Name: "a.service", Enable: true}},
+					},
+				},
+				newConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{{Name: "b.service", Enable: true}},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{Name: "a.service", Enable: true},
+						{Name: "b.service", Enable: true},
+					},
+				},
+			}},
+		},
+
+		// units and dropins with a shared identity are merged
+		{
+			in: in{
+				oldConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{
+							{
+								Name:     "etcd.service",
+								Enable:   true,
+								Contents: "old",
+								Dropins: []types.Dropin{
+									{Name: "a.conf", Contents: "old a"},
+									{Name: "b.conf", Contents: "old b"},
+								},
+							},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{
+							{
+								Name:     "etcd.service",
+								Contents: "new",
+								Dropins: []types.Dropin{
+									{Name: "b.conf", Contents: "new b"},
+									{Name: "c.conf", Contents: "new c"},
+								},
+							},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Name:     "etcd.service",
+							Enable:   true,
+							Contents: "new",
+							Dropins: []types.Dropin{
+								{Name: "a.conf", Contents: "old a"},
+								{Name: "b.conf", Contents: "new b"},
+								{Name: "c.conf", Contents: "new c"},
+							},
+						},
+					},
+				},
+			}},
+		},
+
+		// files are identified by filesystem and path, and their contents
+		// are replaced as a whole
+		{
+			in: in{
+				oldConfig: types.Config{
+					Storage: types.Storage{
+						Files: []types.File{
+							{
+								Node: types.Node{Filesystem: "root", Path: "/etc/hosts", User: types.NodeUser{ID: intToPtr(500)}},
+								FileEmbedded1: types.FileEmbedded1{
+									Mode: 420,
+									Contents: types.FileContents{
+										Source:       "http://example.com/hosts.gz",
+										Compression:  "gzip",
+										Verification: types.Verification{Hash: strToPtr("sha512-0123")},
+									},
+								},
+							},
+							{
+								Node: types.Node{Filesystem: "oem", Path: "/etc/hosts"},
+							},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Storage: types.Storage{
+						Files: []types.File{
+							{
+								Node: types.Node{Filesystem: "root", Path: "/etc/hosts"},
+								FileEmbedded1: types.FileEmbedded1{
+									Contents: types.FileContents{Source: "data:,localhost"},
+								},
+							},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node: types.Node{Filesystem: "root", Path: "/etc/hosts", User: types.NodeUser{ID: intToPtr(500)}},
+							FileEmbedded1: types.FileEmbedded1{
+								Mode:     420,
+								Contents: types.FileContents{Source: "data:,localhost"},
+							},
+						},
+						{
+							Node: types.Node{Filesystem: "oem", Path: "/etc/hosts"},
+						},
+					},
+				},
+			}},
+		},
+
+		// users, groups, disks, and filesystems
+		{
+			in: in{
+				oldConfig: types.Config{
+					Passwd: types.Passwd{
+						Users: []types.PasswdUser{
+							{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"key 1"}},
+						},
+						Groups: []types.PasswdGroup{{Name: "docker", Gid: intToPtr(233)}},
+					},
+					Storage: types.Storage{
+						Disks: []types.Disk{
+							{Device: "/dev/sda", Partitions: []types.Partition{{Number: 1}}},
+						},
+						Filesystems: []types.Filesystem{
+							{Name: "root", Path: strToPtr("/sysroot")},
+						},
+					},
+				},
+				newConfig: types.Config{
+					Passwd: types.Passwd{
+						Users: []types.PasswdUser{
+							{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"key 2"}, Shell: "/bin/zsh"},
+						},
+						Groups: []types.PasswdGroup{{Name: "docker", System: true}},
+					},
+					Storage: types.Storage{
+						Disks: []types.Disk{
+							{Device: "/dev/sda", WipeTable: true, Partitions: []types.Partition{{Number: 2}}},
+						},
+						Filesystems: []types.Filesystem{
+							{Name: "root", Path: strToPtr("/")},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{
+						{Name: "core", SSHAuthorizedKeys: []types.SSHAuthorizedKey{"key 1", "key 2"}, Shell: "/bin/zsh"},
+					},
+					Groups: []types.PasswdGroup{{Name: "docker", Gid: intToPtr(233), System: true}},
+				},
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{Device: "/dev/sda", WipeTable: true, Partitions: []types.Partition{{Number: 1}, {Number: 2}}},
+					},
+					Filesystems: []types.Filesystem{
+						{Name: "root", Path: strToPtr("/")},
+					},
+				},
+			}},
+		},
+
+		// duplicates within the new config are merged without old entries
+		{
+			in: in{
+				oldConfig: types.Config{},
+				newConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{
+							{Name: "a.service", Contents: "[Service]"},
+							{Name: "a.service", Enable: true},
+						},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Systemd: types.Systemd{
+					Units: []types.Unit{{Name: "a.service", Contents: "[Service]", Enable: true}},
+				},
+			}},
+		},
+
+		// booleans can't be overridden to false
+		{
+			in: in{
+				oldConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{{Name: "a.service", Enable: true}},
+					},
+				},
+				newConfig: types.Config{
+					Systemd: types.Systemd{
+						Units: []types.Unit{{Name: "a.service", Enable: false}},
+					},
+				},
+			},
+			out: out{config: types.Config{
+				Systemd: types.Systemd{
+					Units: []types.Unit{{Name: "a.service", Enable: true}},
+				},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		config := Merge(test.in.oldConfig, test.in.newConfig)
+		if !reflect.DeepEqual(test.out.config, config) {
+			t.Errorf("#%d: bad config: want %+v, got %+v", i, test.out.config, config)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/coreos/ignition/config/types"
+)
+
+// The values Ignition uses for unset options.
+const (
+	DefaultFileMode                   = 0644
+	DefaultDirectoryMode              = 0755
+	DefaultHTTPResponseHeadersTimeout = 10 // seconds
+	DefaultHTTPTotalTimeout           = 0  // seconds (no limit)
+	DefaultID                         = 0  // root, for unset users and groups
+)
+
+// Render returns the config Ignition applies given the user-provided cfg: cfg
+// merged over the OEM base config and the mapping of the "root" filesystem to
+// root, with every default made explicit (see Normalize). References to other
+// configs are not fetched; cfg is expected to be the result of evaluating
+// them.
+func Render(cfg, oemBaseConfig types.Config, root string) types.Config {
+	base := types.Config{
+		Ignition: types.Ignition{Version: types.MaxVersion.String()},
+		Storage: types.Storage{
+			Filesystems: []types.Filesystem{{
+				Name: "root",
+				Path: &root,
+			}},
+		},
+	}
+	return Normalize(Merge(base, Merge(oemBaseConfig, cfg)))
+}
+
+// Normalize returns a copy of cfg in which every option that has a default is
+// set explicitly:
+//   - the HTTP timeouts are set to their defaults
+//   - files without a mode get DefaultFileMode and directories without a mode
+//     get DefaultDirectoryMode
+//   - nodes without a user or group (by ID or name) are owned by root
+//   - nodes without an overwrite policy get the default for their type (see
+//     File.ShouldOverwrite and friends)
+func Normalize(cfg types.Config) types.Config {
+	if cfg.Ignition.Timeouts.HTTPResponseHeaders == nil {
+		cfg.Ignition.Timeouts.HTTPResponseHeaders = intToPtr(DefaultHTTPResponseHeadersTimeout)
+	}
+	if cfg.Ignition.Timeouts.HTTPTotal == nil {
+		cfg.Ignition.Timeouts.HTTPTotal = intToPtr(DefaultHTTPTotalTimeout)
+	}
+
+	// Copy the lists so that normalizing doesn't modify the caller's config.
+	cfg.Storage.Files = append([]types.File(nil), cfg.Storage.Files...)
+	for i, f := range cfg.Storage.Files {
+		if f.Mode == 0 {
+			cfg.Storage.Files[i].Mode = DefaultFileMode
+		}
+		cfg.Storage.Files[i].Node = normalizeNode(f.Node)
+		cfg.Storage.Files[i].Overwrite = boolToPtr(f.ShouldOverwrite())
+	}
+
+	cfg.Storage.Directories = append([]types.Directory(nil), cfg.Storage.Directories...)
+	for i, d := range cfg.Storage.Directories {
+		if d.Mode == 0 {
+			cfg.Storage.Directories[i].Mode = DefaultDirectoryMode
+		}
+		cfg.Storage.Directories[i].Node = normalizeNode(d.Node)
+		cfg.Storage.Directories[i].Overwrite = boolToPtr(d.ShouldOverwrite())
+	}
+
+	cfg.Storage.Links = append([]types.Link(nil), cfg.Storage.Links...)
+	for i, l := range cfg.Storage.Links {
+		cfg.Storage.Links[i].Node = normalizeNode(l.Node)
+		cfg.Storage.Links[i].Overwrite = boolToPtr(l.ShouldOverwrite())
+	}
+
+	return cfg
+}
+
+// normalizeNode sets the user and group of n to root unless they are given.
+// Names are left in place since they can only be resolved on the target
+// system.
+func normalizeNode(n types.Node) types.Node {
+	if n.User.ID == nil && n.User.Name == "" {
+		n.User.ID = intToPtr(DefaultID)
+	}
+	if n.Group.ID == nil && n.Group.Name == "" {
+		n.Group.ID = intToPtr(DefaultID)
+	}
+	return n
+}
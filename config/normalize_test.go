@@ -0,0 +1,138 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestNormalize(t *testing.T) {
+	type in struct {
+		config types.Config
+	}
+	type out struct {
+		config types.Config
+	}
+
+	timeouts := types.Timeouts{
+		HTTPResponseHeaders: intToPtr(DefaultHTTPResponseHeadersTimeout),
+		HTTPTotal:           intToPtr(DefaultHTTPTotalTimeout),
+	}
+	root := types.NodeUser{ID: intToPtr(0)}
+	rootGroup := types.NodeGroup{ID: intToPtr(0)}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{config: types.Config{}},
+			out: out{config: types.Config{Ignition: types.Ignition{Timeouts: timeouts}}},
+		},
+		{
+			in: in{config: types.Config{
+				Ignition: types.Ignition{Timeouts: types.Timeouts{HTTPResponseHeaders: intToPtr(30)}},
+				Storage: types.Storage{
+					Files: []types.File{
+						{Node: types.Node{Filesystem: "root", Path: "/a"}},
+						{
+							Node:          types.Node{Filesystem: "root", Path: "/b", User: types.NodeUser{Name: "core"}, Overwrite: boolToPtr(false)},
+							FileEmbedded1: types.FileEmbedded1{Mode: 0600},
+						},
+					},
+					Directories: []types.Directory{
+						{Node: types.Node{Filesystem: "root", Path: "/c", Group: types.NodeGroup{ID: intToPtr(500)}}},
+					},
+					Links: []types.Link{
+						{Node: types.Node{Filesystem: "root", Path: "/d"}, LinkEmbedded1: types.LinkEmbedded1{Target: "/a"}},
+					},
+				},
+			}},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Timeouts: types.Timeouts{
+					HTTPResponseHeaders: intToPtr(30),
+					HTTPTotal:           intToPtr(DefaultHTTPTotalTimeout),
+				}},
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node:          types.Node{Filesystem: "root", Path: "/a", User: root, Group: rootGroup, Overwrite: boolToPtr(true)},
+							FileEmbedded1: types.FileEmbedded1{Mode: DefaultFileMode},
+						},
+						{
+							Node:          types.Node{Filesystem: "root", Path: "/b", User: types.NodeUser{Name: "core"}, Group: rootGroup, Overwrite: boolToPtr(false)},
+							FileEmbedded1: types.FileEmbedded1{Mode: 0600},
+						},
+					},
+					Directories: []types.Directory{
+						{
+							Node:               types.Node{Filesystem: "root", Path: "/c", User: root, Group: types.NodeGroup{ID: intToPtr(500)}, Overwrite: boolToPtr(false)},
+							DirectoryEmbedded1: types.DirectoryEmbedded1{Mode: DefaultDirectoryMode},
+						},
+					},
+					Links: []types.Link{
+						{
+							Node:          types.Node{Filesystem: "root", Path: "/d", User: root, Group: rootGroup, Overwrite: boolToPtr(false)},
+							LinkEmbedded1: types.LinkEmbedded1{Target: "/a"},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		config := Normalize(test.in.config)
+		if !reflect.DeepEqual(test.out.config, config) {
+			t.Errorf("#%d: bad config: want %+v, got %+v", i, test.out.config, config)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	sysroot := "/sysroot"
+	cfg := types.Config{
+		Ignition: types.Ignition{Version: "2.0.0"},
+		Systemd:  types.Systemd{Units: []types.Unit{{Name: "a.service", Enable: true}}},
+	}
+	oemBaseConfig := types.Config{
+		Systemd: types.Systemd{Units: []types.Unit{
+			{Name: "a.service", Dropins: []types.Dropin{{Name: "10-oem.conf", Contents: "[Service]"}}},
+			{Name: "oem.service", Enable: true},
+		}},
+	}
+
+	expected := types.Config{
+		Ignition: types.Ignition{
+			Version: types.MaxVersion.String(),
+			Timeouts: types.Timeouts{
+				HTTPResponseHeaders: intToPtr(DefaultHTTPResponseHeadersTimeout),
+				HTTPTotal:           intToPtr(DefaultHTTPTotalTimeout),
+			},
+		},
+		Storage: types.Storage{Filesystems: []types.Filesystem{{Name: "root", Path: &sysroot}}},
+		Systemd: types.Systemd{Units: []types.Unit{
+			{Name: "a.service", Enable: true, Dropins: []types.Dropin{{Name: "10-oem.conf", Contents: "[Service]"}}},
+			{Name: "oem.service", Enable: true},
+		}},
+	}
+
+	if config := Render(cfg, oemBaseConfig, sysroot); !reflect.DeepEqual(expected, config) {
+		t.Errorf("bad config: want %+v, got %+v", expected, config)
+	}
+}
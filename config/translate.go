@@ -15,6 +15,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -22,14 +23,24 @@ import (
 	"github.com/coreos/ignition/config/types"
 	v1 "github.com/coreos/ignition/config/v1/types"
 	v2_0 "github.com/coreos/ignition/config/v2_0/types"
+	v2_1 "github.com/coreos/ignition/config/v2_1/types"
+	"github.com/coreos/ignition/config/validate/report"
 
 	"github.com/vincent-petithory/dataurl"
 )
 
+var (
+	ErrLossyTranslation = errors.New("config cannot be translated without losing information")
+)
+
 func intToPtr(x int) *int {
 	return &x
 }
 
+func boolToPtr(b bool) *bool {
+	return &b
+}
+
 func strToPtr(s string) *string {
 	if s == "" {
 		return nil
@@ -412,3 +423,943 @@ func translateV2_0MkfsOptionsToV2_1OptionSlice(opts v2_0.MkfsOptions) []types.Cr
 	}
 	return newOpts
 }
+
+func TranslateFromV2_1(old v2_1.Config) types.Config {
+	translateConfigReference := func(old *v2_1.ConfigReference) *types.ConfigReference {
+		if old == nil {
+			return nil
+		}
+		return &types.ConfigReference{
+			Source: old.Source,
+			Verification: types.Verification{
+				Hash: old.Verification.Hash,
+			},
+		}
+	}
+	translateConfigReferenceSlice := func(old []v2_1.ConfigReference) []types.ConfigReference {
+		var res []types.ConfigReference
+		for _, c := range old {
+			res = append(res, *translateConfigReference(&c))
+		}
+		return res
+	}
+	translateNetworkdUnitSlice := func(old []v2_1.Networkdunit) []types.Networkdunit {
+		var res []types.Networkdunit
+		for _, u := range old {
+			res = append(res, types.Networkdunit{
+				Contents: u.Contents,
+				Name:     u.Name,
+			})
+		}
+		return res
+	}
+	translatePasswdGroupSlice := func(old []v2_1.PasswdGroup) []types.PasswdGroup {
+		var res []types.PasswdGroup
+		for _, g := range old {
+			res = append(res, types.PasswdGroup{
+				Gid:          g.Gid,
+				Name:         g.Name,
+				PasswordHash: g.PasswordHash,
+				System:       g.System,
+			})
+		}
+		return res
+	}
+	translatePasswdUsercreateGroupSlice := func(old []v2_1.UsercreateGroup) []types.UsercreateGroup {
+		var res []types.UsercreateGroup
+		for _, g := range old {
+			res = append(res, types.UsercreateGroup(g))
+		}
+		return res
+	}
+	translatePasswdUsercreate := func(old *v2_1.Usercreate) *types.Usercreate {
+		if old == nil {
+			return nil
+		}
+		return &types.Usercreate{
+			Gecos:        old.Gecos,
+			Groups:       translatePasswdUsercreateGroupSlice(old.Groups),
+			HomeDir:      old.HomeDir,
+			NoCreateHome: old.NoCreateHome,
+			NoLogInit:    old.NoLogInit,
+			NoUserGroup:  old.NoUserGroup,
+			PrimaryGroup: old.PrimaryGroup,
+			Shell:        old.Shell,
+			System:       old.System,
+			UID:          old.UID,
+		}
+	}
+	translatePasswdUserGroupSlice := func(old []v2_1.Group) []types.Group {
+		var res []types.Group
+		for _, g := range old {
+			res = append(res, types.Group(g))
+		}
+		return res
+	}
+	translatePasswdSSHAuthorizedKeySlice := func(old []v2_1.SSHAuthorizedKey) []types.SSHAuthorizedKey {
+		var res []types.SSHAuthorizedKey
+		for _, k := range old {
+			res = append(res, types.SSHAuthorizedKey(k))
+		}
+		return res
+	}
+	translatePasswdUserSlice := func(old []v2_1.PasswdUser) []types.PasswdUser {
+		var res []types.PasswdUser
+		for _, u := range old {
+			res = append(res, types.PasswdUser{
+				Create:            translatePasswdUsercreate(u.Create),
+				Gecos:             u.Gecos,
+				Groups:            translatePasswdUserGroupSlice(u.Groups),
+				HomeDir:           u.HomeDir,
+				Name:              u.Name,
+				NoCreateHome:      u.NoCreateHome,
+				NoLogInit:         u.NoLogInit,
+				NoUserGroup:       u.NoUserGroup,
+				PasswordHash:      u.PasswordHash,
+				PrimaryGroup:      u.PrimaryGroup,
+				SSHAuthorizedKeys: translatePasswdSSHAuthorizedKeySlice(u.SSHAuthorizedKeys),
+				Shell:             u.Shell,
+				System:            u.System,
+				UID:               u.UID,
+			})
+		}
+		return res
+	}
+	translateNodeGroup := func(old v2_1.NodeGroup) types.NodeGroup {
+		return types.NodeGroup{
+			ID:   old.ID,
+			Name: old.Name,
+		}
+	}
+	translateNodeUser := func(old v2_1.NodeUser) types.NodeUser {
+		return types.NodeUser{
+			ID:   old.ID,
+			Name: old.Name,
+		}
+	}
+	translateNode := func(old v2_1.Node) types.Node {
+		return types.Node{
+			Filesystem: old.Filesystem,
+			Group:      translateNodeGroup(old.Group),
+			Path:       old.Path,
+			User:       translateNodeUser(old.User),
+		}
+	}
+	translateDirectorySlice := func(old []v2_1.Directory) []types.Directory {
+		var res []types.Directory
+		for _, d := range old {
+			res = append(res, types.Directory{
+				Node: translateNode(d.Node),
+				DirectoryEmbedded1: types.DirectoryEmbedded1{
+					Mode: d.Mode,
+				},
+			})
+		}
+		return res
+	}
+	translatePartitionSlice := func(old []v2_1.Partition) []types.Partition {
+		var res []types.Partition
+		for _, p := range old {
+			res = append(res, types.Partition{
+				GUID:     p.GUID,
+				Label:    p.Label,
+				Number:   p.Number,
+				Size:     p.Size,
+				Start:    p.Start,
+				TypeGUID: p.TypeGUID,
+			})
+		}
+		return res
+	}
+	translateDiskSlice := func(old []v2_1.Disk) []types.Disk {
+		var res []types.Disk
+		for _, d := range old {
+			res = append(res, types.Disk{
+				Device:     d.Device,
+				Partitions: translatePartitionSlice(d.Partitions),
+				WipeTable:  d.WipeTable,
+			})
+		}
+		return res
+	}
+	translateFileSlice := func(old []v2_1.File) []types.File {
+		var res []types.File
+		for _, f := range old {
+			res = append(res, types.File{
+				Node: translateNode(f.Node),
+				FileEmbedded1: types.FileEmbedded1{
+					Contents: types.FileContents{
+						Compression: f.Contents.Compression,
+						Source:      f.Contents.Source,
+						Verification: types.Verification{
+							Hash: f.Contents.Verification.Hash,
+						},
+					},
+					Mode: f.Mode,
+				},
+			})
+		}
+		return res
+	}
+	translateMountCreateOptionSlice := func(old []v2_1.CreateOption) []types.CreateOption {
+		var res []types.CreateOption
+		for _, o := range old {
+			res = append(res, types.CreateOption(o))
+		}
+		return res
+	}
+	translateMountCreate := func(old *v2_1.Create) *types.Create {
+		if old == nil {
+			return nil
+		}
+		return &types.Create{
+			Force:   old.Force,
+			Options: translateMountCreateOptionSlice(old.Options),
+		}
+	}
+	translateMountOptionSlice := func(old []v2_1.MountOption) []types.MountOption {
+		var res []types.MountOption
+		for _, o := range old {
+			res = append(res, types.MountOption(o))
+		}
+		return res
+	}
+	translateMount := func(old *v2_1.Mount) *types.Mount {
+		if old == nil {
+			return nil
+		}
+		return &types.Mount{
+			Create:         translateMountCreate(old.Create),
+			Device:         old.Device,
+			Format:         old.Format,
+			Label:          old.Label,
+			Options:        translateMountOptionSlice(old.Options),
+			UUID:           old.UUID,
+			WipeFilesystem: old.WipeFilesystem,
+		}
+	}
+	translateFilesystemSlice := func(old []v2_1.Filesystem) []types.Filesystem {
+		var res []types.Filesystem
+		for _, f := range old {
+			res = append(res, types.Filesystem{
+				Mount: translateMount(f.Mount),
+				Name:  f.Name,
+				Path:  f.Path,
+			})
+		}
+		return res
+	}
+	translateLinkSlice := func(old []v2_1.Link) []types.Link {
+		var res []types.Link
+		for _, l := range old {
+			res = append(res, types.Link{
+				Node: translateNode(l.Node),
+				LinkEmbedded1: types.LinkEmbedded1{
+					Hard:   l.Hard,
+					Target: l.Target,
+				},
+			})
+		}
+		return res
+	}
+	translateDeviceSlice := func(old []v2_1.Device) []types.Device {
+		var res []types.Device
+		for _, d := range old {
+			res = append(res, types.Device(d))
+		}
+		return res
+	}
+	translateRaidSlice := func(old []v2_1.Raid) []types.Raid {
+		var res []types.Raid
+		for _, r := range old {
+			res = append(res, types.Raid{
+				Devices: translateDeviceSlice(r.Devices),
+				Level:   r.Level,
+				Name:    r.Name,
+				Spares:  r.Spares,
+			})
+		}
+		return res
+	}
+	translateSystemdDropinSlice := func(old []v2_1.Dropin) []types.Dropin {
+		var res []types.Dropin
+		for _, d := range old {
+			res = append(res, types.Dropin{
+				Contents: d.Contents,
+				Name:     d.Name,
+			})
+		}
+		return res
+	}
+	translateSystemdUnitSlice := func(old []v2_1.Unit) []types.Unit {
+		var res []types.Unit
+		for _, u := range old {
+			res = append(res, types.Unit{
+				Contents: u.Contents,
+				Dropins:  translateSystemdDropinSlice(u.Dropins),
+				Enable:   u.Enable,
+				Mask:     u.Mask,
+				Name:     u.Name,
+			})
+		}
+		return res
+	}
+
+	config := types.Config{
+		Ignition: types.Ignition{
+			Version: types.MaxVersion.String(),
+			Timeouts: types.Timeouts{
+				HTTPResponseHeaders: old.Ignition.Timeouts.HTTPResponseHeaders,
+				HTTPTotal:           old.Ignition.Timeouts.HTTPTotal,
+			},
+			Config: types.IgnitionConfig{
+				Replace: translateConfigReference(old.Ignition.Config.Replace),
+				Append:  translateConfigReferenceSlice(old.Ignition.Config.Append),
+			},
+		},
+		Networkd: types.Networkd{
+			Units: translateNetworkdUnitSlice(old.Networkd.Units),
+		},
+		Passwd: types.Passwd{
+			Groups: translatePasswdGroupSlice(old.Passwd.Groups),
+			Users:  translatePasswdUserSlice(old.Passwd.Users),
+		},
+		Storage: types.Storage{
+			Directories: translateDirectorySlice(old.Storage.Directories),
+			Disks:       translateDiskSlice(old.Storage.Disks),
+			Files:       translateFileSlice(old.Storage.Files),
+			Filesystems: translateFilesystemSlice(old.Storage.Filesystems),
+			Links:       translateLinkSlice(old.Storage.Links),
+			Raid:        translateRaidSlice(old.Storage.Raid),
+		},
+		Systemd: types.Systemd{
+			Units: translateSystemdUnitSlice(old.Systemd.Units),
+		},
+	}
+
+	return config
+}
+
+// lossyReport collects the fields of a config that have no equivalent in the
+// version it is being translated to.
+type lossyReport struct {
+	report.Report
+	version string
+}
+
+func (r *lossyReport) lossy(format string, a ...interface{}) {
+	r.Add(report.Entry{
+		Kind:    report.EntryError,
+		Message: fmt.Sprintf("%s cannot be represented in config version %s", fmt.Sprintf(format, a...), r.version),
+	})
+}
+
+// checkUnrepresentableUser reports the user-level account fields, which older
+// versions can only express through the create object (which has different
+// semantics for users that already exist).
+func (r *lossyReport) checkUnrepresentableUser(i int, u types.PasswdUser) {
+	if u.UID != nil {
+		r.lossy("passwd.users[%d].uid", i)
+	}
+	if u.Gecos != "" {
+		r.lossy("passwd.users[%d].gecos", i)
+	}
+	if u.HomeDir != "" {
+		r.lossy("passwd.users[%d].homeDir", i)
+	}
+	if u.NoCreateHome {
+		r.lossy("passwd.users[%d].noCreateHome", i)
+	}
+	if u.PrimaryGroup != "" {
+		r.lossy("passwd.users[%d].primaryGroup", i)
+	}
+	if len(u.Groups) > 0 {
+		r.lossy("passwd.users[%d].groups", i)
+	}
+	if u.NoUserGroup {
+		r.lossy("passwd.users[%d].noUserGroup", i)
+	}
+	if u.NoLogInit {
+		r.lossy("passwd.users[%d].noLogInit", i)
+	}
+	if u.Shell != "" {
+		r.lossy("passwd.users[%d].shell", i)
+	}
+	if u.System {
+		r.lossy("passwd.users[%d].system", i)
+	}
+	if u.PasswordHash != nil && *u.PasswordHash == "" {
+		r.lossy("passwd.users[%d].passwordHash (empty)", i)
+	}
+}
+
+// checkUnrepresentableMount reports the mount fields which were added after
+// config version 2.0.0.
+func (r *lossyReport) checkUnrepresentableMount(i int, m types.Mount) {
+	switch m.Format {
+	case "ext4", "btrfs", "xfs":
+	default:
+		r.lossy("storage.filesystems[%d].mount.format %q", i, m.Format)
+	}
+	if m.Label != nil {
+		r.lossy("storage.filesystems[%d].mount.label", i)
+	}
+	if m.UUID != nil {
+		r.lossy("storage.filesystems[%d].mount.uuid", i)
+	}
+	if len(m.Options) > 0 {
+		r.lossy("storage.filesystems[%d].mount.options", i)
+	}
+	if m.WipeFilesystem {
+		r.lossy("storage.filesystems[%d].mount.wipeFilesystem", i)
+	}
+	if m.Path != nil {
+		r.lossy("storage.filesystems[%d].mount.path", i)
+	}
+	if len(m.MountOptions) > 0 {
+		r.lossy("storage.filesystems[%d].mount.mountOptions", i)
+	}
+}
+
+// TranslateToV2_0 translates the config into a version 2.0.0 config. Every
+// field which cannot be represented in version 2.0.0 is listed in the
+// returned report and ErrLossyTranslation is returned instead of a config.
+func TranslateToV2_0(cfg types.Config) (v2_0.Config, report.Report, error) {
+	r := lossyReport{version: v2_0.MaxVersion.String()}
+
+	translateVerification := func(field string, old types.Verification) v2_0.Verification {
+		var ver v2_0.Verification
+		if old.Hash != nil {
+			function, sum, err := old.HashParts()
+			if err != nil {
+				r.lossy("%s.hash (%v)", field, err)
+				return ver
+			}
+			if function != "sha512" {
+				r.lossy("%s.hash (%s)", field, function)
+				return ver
+			}
+			ver.Hash = &v2_0.Hash{Function: function, Sum: sum}
+		}
+		if len(old.Hashes) > 0 {
+			r.lossy("%s.hashes", field)
+		}
+		if old.Signature != nil {
+			r.lossy("%s.signature", field)
+		}
+		return ver
+	}
+	translateUrl := func(field string, old string) v2_0.Url {
+		u, err := url.Parse(old)
+		if err != nil {
+			r.lossy("%s (%v)", field, err)
+			return v2_0.Url{}
+		}
+		return v2_0.Url(*u)
+	}
+	translateConfigReference := func(field string, old types.ConfigReference) v2_0.ConfigReference {
+		return v2_0.ConfigReference{
+			Source:       translateUrl(field+".source", old.Source),
+			Verification: translateVerification(field+".verification", old.Verification),
+		}
+	}
+
+	config := v2_0.Config{
+		Ignition: v2_0.Ignition{
+			Version: v2_0.IgnitionVersion(v2_0.MaxVersion),
+		},
+	}
+
+	if old := cfg.Ignition.Config.Replace; old != nil {
+		ref := translateConfigReference("ignition.config.replace", *old)
+		config.Ignition.Config.Replace = &ref
+	}
+
+	for i, old := range cfg.Ignition.Config.Append {
+		config.Ignition.Config.Append = append(config.Ignition.Config.Append,
+			translateConfigReference(fmt.Sprintf("ignition.config.append[%d]", i), old))
+	}
+
+	if len(cfg.Ignition.Security.TrustedKeys) > 0 {
+		r.lossy("ignition.security.trustedKeys")
+	}
+	if cfg.Ignition.Timeouts.HTTPResponseHeaders != nil {
+		r.lossy("ignition.timeouts.httpResponseHeaders")
+	}
+	if cfg.Ignition.Timeouts.HTTPTotal != nil {
+		r.lossy("ignition.timeouts.httpTotal")
+	}
+
+	for i, oldDisk := range cfg.Storage.Disks {
+		disk := v2_0.Disk{
+			Device:    v2_0.Path(oldDisk.Device),
+			WipeTable: oldDisk.WipeTable,
+		}
+
+		for j, oldPartition := range oldDisk.Partitions {
+			if oldPartition.GUID != "" {
+				r.lossy("storage.disks[%d].partitions[%d].guid", i, j)
+			}
+			disk.Partitions = append(disk.Partitions, v2_0.Partition{
+				Label:    v2_0.PartitionLabel(oldPartition.Label),
+				Number:   oldPartition.Number,
+				Size:     v2_0.PartitionDimension(oldPartition.Size),
+				Start:    v2_0.PartitionDimension(oldPartition.Start),
+				TypeGUID: v2_0.PartitionTypeGUID(oldPartition.TypeGUID),
+			})
+		}
+
+		config.Storage.Disks = append(config.Storage.Disks, disk)
+	}
+
+	for _, oldArray := range cfg.Storage.Raid {
+		array := v2_0.Raid{
+			Name:   oldArray.Name,
+			Level:  oldArray.Level,
+			Spares: oldArray.Spares,
+		}
+
+		for _, oldDevice := range oldArray.Devices {
+			array.Devices = append(array.Devices, v2_0.Path(oldDevice))
+		}
+
+		config.Storage.Arrays = append(config.Storage.Arrays, array)
+	}
+
+	for i, oldFilesystem := range cfg.Storage.Filesystems {
+		filesystem := v2_0.Filesystem{
+			Name: oldFilesystem.Name,
+		}
+
+		if oldFilesystem.Mount != nil {
+			r.checkUnrepresentableMount(i, *oldFilesystem.Mount)
+
+			filesystem.Mount = &v2_0.FilesystemMount{
+				Device: v2_0.Path(oldFilesystem.Mount.Device),
+				Format: v2_0.FilesystemFormat(oldFilesystem.Mount.Format),
+			}
+
+			if oldFilesystem.Mount.Create != nil {
+				filesystem.Mount.Create = &v2_0.FilesystemCreate{
+					Force:   oldFilesystem.Mount.Create.Force,
+					Options: translateV2_1OptionSliceToV2_0MkfsOptions(oldFilesystem.Mount.Create.Options),
+				}
+			}
+		}
+
+		if oldFilesystem.Path != nil {
+			p := v2_0.Path(*oldFilesystem.Path)
+			filesystem.Path = &p
+		}
+
+		config.Storage.Filesystems = append(config.Storage.Filesystems, filesystem)
+	}
+
+	for i, oldFile := range cfg.Storage.Files {
+		if oldFile.User.Name != "" {
+			r.lossy("storage.files[%d].user.name", i)
+		}
+		if oldFile.Group.Name != "" {
+			r.lossy("storage.files[%d].group.name", i)
+		}
+		if oldFile.Contents.Template {
+			r.lossy("storage.files[%d].contents.template", i)
+		}
+		if c := oldFile.Contents.Compression; c != "" && c != "gzip" {
+			r.lossy("storage.files[%d].contents.compression %q", i, c)
+		}
+		if len(oldFile.Append) > 0 {
+			r.lossy("storage.files[%d].append", i)
+		}
+		if !oldFile.ShouldOverwrite() {
+			r.lossy("storage.files[%d].overwrite", i)
+		}
+
+		file := v2_0.File{
+			Filesystem: oldFile.Filesystem,
+			Path:       v2_0.Path(oldFile.Path),
+			Mode:       v2_0.FileMode(oldFile.Mode),
+			Contents: v2_0.FileContents{
+				Compression:  v2_0.Compression(oldFile.Contents.Compression),
+				Source:       translateUrl(fmt.Sprintf("storage.files[%d].contents.source", i), oldFile.Contents.Source),
+				Verification: translateVerification(fmt.Sprintf("storage.files[%d].contents.verification", i), oldFile.Contents.Verification),
+			},
+		}
+		if oldFile.User.ID != nil {
+			file.User.Id = *oldFile.User.ID
+		}
+		if oldFile.Group.ID != nil {
+			file.Group.Id = *oldFile.Group.ID
+		}
+
+		config.Storage.Files = append(config.Storage.Files, file)
+	}
+
+	for i := range cfg.Storage.Directories {
+		r.lossy("storage.directories[%d]", i)
+	}
+
+	for i := range cfg.Storage.Links {
+		r.lossy("storage.links[%d]", i)
+	}
+
+	for i := range cfg.Storage.Remove {
+		r.lossy("storage.remove[%d]", i)
+	}
+
+	for i, oldUnit := range cfg.Systemd.Units {
+		if oldUnit.Template {
+			r.lossy("systemd.units[%d].template", i)
+		}
+
+		unit := v2_0.SystemdUnit{
+			Name:     v2_0.SystemdUnitName(oldUnit.Name),
+			Enable:   oldUnit.Enable,
+			Mask:     oldUnit.Mask,
+			Contents: oldUnit.Contents,
+		}
+
+		for _, oldDropin := range oldUnit.Dropins {
+			unit.DropIns = append(unit.DropIns, v2_0.SystemdUnitDropIn{
+				Name:     v2_0.SystemdUnitDropInName(oldDropin.Name),
+				Contents: oldDropin.Contents,
+			})
+		}
+
+		config.Systemd.Units = append(config.Systemd.Units, unit)
+	}
+
+	for _, oldUnit := range cfg.Networkd.Units {
+		config.Networkd.Units = append(config.Networkd.Units, v2_0.NetworkdUnit{
+			Name:     v2_0.NetworkdUnitName(oldUnit.Name),
+			Contents: oldUnit.Contents,
+		})
+	}
+
+	for i, oldUser := range cfg.Passwd.Users {
+		r.checkUnrepresentableUser(i, oldUser)
+
+		user := v2_0.User{
+			Name:              oldUser.Name,
+			SSHAuthorizedKeys: translateV2_1SSHAuthorizedKeySliceToStringSlice(oldUser.SSHAuthorizedKeys),
+		}
+		if oldUser.PasswordHash != nil {
+			user.PasswordHash = *oldUser.PasswordHash
+		}
+
+		if oldUser.Create != nil {
+			var uid *uint
+			if oldUser.Create.UID != nil {
+				if *oldUser.Create.UID < 0 {
+					r.lossy("passwd.users[%d].create.uid (negative)", i)
+				}
+				tmp := uint(*oldUser.Create.UID)
+				uid = &tmp
+			}
+			user.Create = &v2_0.UserCreate{
+				Uid:          uid,
+				GECOS:        oldUser.Create.Gecos,
+				Homedir:      oldUser.Create.HomeDir,
+				NoCreateHome: oldUser.Create.NoCreateHome,
+				PrimaryGroup: oldUser.Create.PrimaryGroup,
+				Groups:       translateV2_1UsercreateGroupSliceToStringSlice(oldUser.Create.Groups),
+				NoUserGroup:  oldUser.Create.NoUserGroup,
+				System:       oldUser.Create.System,
+				NoLogInit:    oldUser.Create.NoLogInit,
+				Shell:        oldUser.Create.Shell,
+			}
+		}
+
+		config.Passwd.Users = append(config.Passwd.Users, user)
+	}
+
+	for i, oldGroup := range cfg.Passwd.Groups {
+		var gid *uint
+		if oldGroup.Gid != nil {
+			if *oldGroup.Gid < 0 {
+				r.lossy("passwd.groups[%d].gid (negative)", i)
+			}
+			tmp := uint(*oldGroup.Gid)
+			gid = &tmp
+		}
+		config.Passwd.Groups = append(config.Passwd.Groups, v2_0.Group{
+			Name:         oldGroup.Name,
+			Gid:          gid,
+			PasswordHash: oldGroup.PasswordHash,
+			System:       oldGroup.System,
+		})
+	}
+
+	if r.IsFatal() {
+		return v2_0.Config{}, r.Report, ErrLossyTranslation
+	}
+	return config, r.Report, nil
+}
+
+// TranslateToV1 translates the config into a version 1 config. Every field
+// which cannot be represented in version 1 is listed in the returned report
+// and ErrLossyTranslation is returned instead of a config.
+func TranslateToV1(cfg types.Config) (v1.Config, report.Report, error) {
+	r := lossyReport{version: "1"}
+
+	config := v1.Config{
+		Version: v1.Version,
+	}
+
+	if cfg.Ignition.Config.Replace != nil {
+		r.lossy("ignition.config.replace")
+	}
+	for i := range cfg.Ignition.Config.Append {
+		r.lossy("ignition.config.append[%d]", i)
+	}
+	if len(cfg.Ignition.Security.TrustedKeys) > 0 {
+		r.lossy("ignition.security.trustedKeys")
+	}
+	if cfg.Ignition.Timeouts.HTTPResponseHeaders != nil {
+		r.lossy("ignition.timeouts.httpResponseHeaders")
+	}
+	if cfg.Ignition.Timeouts.HTTPTotal != nil {
+		r.lossy("ignition.timeouts.httpTotal")
+	}
+
+	for i, oldDisk := range cfg.Storage.Disks {
+		disk := v1.Disk{
+			Device:    v1.Path(oldDisk.Device),
+			WipeTable: oldDisk.WipeTable,
+		}
+
+		for j, oldPartition := range oldDisk.Partitions {
+			if oldPartition.GUID != "" {
+				r.lossy("storage.disks[%d].partitions[%d].guid", i, j)
+			}
+			disk.Partitions = append(disk.Partitions, v1.Partition{
+				Label:    v1.PartitionLabel(oldPartition.Label),
+				Number:   oldPartition.Number,
+				Size:     v1.PartitionDimension(oldPartition.Size),
+				Start:    v1.PartitionDimension(oldPartition.Start),
+				TypeGUID: v1.PartitionTypeGUID(oldPartition.TypeGUID),
+			})
+		}
+
+		config.Storage.Disks = append(config.Storage.Disks, disk)
+	}
+
+	for _, oldArray := range cfg.Storage.Raid {
+		array := v1.Raid{
+			Name:   oldArray.Name,
+			Level:  oldArray.Level,
+			Spares: oldArray.Spares,
+		}
+
+		for _, oldDevice := range oldArray.Devices {
+			array.Devices = append(array.Devices, v1.Path(oldDevice))
+		}
+
+		config.Storage.Arrays = append(config.Storage.Arrays, array)
+	}
+
+	// Version 1 nests files inside of their filesystem, so keep track of
+	// where each named filesystem ended up. As in the files stage, the last
+	// definition of a filesystem wins.
+	filesystems := map[string]int{}
+	for i, oldFilesystem := range cfg.Storage.Filesystems {
+		if oldFilesystem.Mount == nil {
+			r.lossy("storage.filesystems[%d].path", i)
+			continue
+		}
+		r.checkUnrepresentableMount(i, *oldFilesystem.Mount)
+
+		filesystem := v1.Filesystem{
+			Device: v1.Path(oldFilesystem.Mount.Device),
+			Format: v1.FilesystemFormat(oldFilesystem.Mount.Format),
+		}
+
+		if oldFilesystem.Mount.Create != nil {
+			filesystem.Create = &v1.FilesystemCreate{
+				Force:   oldFilesystem.Mount.Create.Force,
+				Options: v1.MkfsOptions(translateV2_1OptionSliceToV2_0MkfsOptions(oldFilesystem.Mount.Create.Options)),
+			}
+		}
+
+		filesystems[oldFilesystem.Name] = len(config.Storage.Filesystems)
+		config.Storage.Filesystems = append(config.Storage.Filesystems, filesystem)
+	}
+
+	for i, oldFile := range cfg.Storage.Files {
+		idx, ok := filesystems[oldFile.Filesystem]
+		if !ok {
+			r.lossy("storage.files[%d].filesystem %q", i, oldFile.Filesystem)
+			continue
+		}
+		if oldFile.User.Name != "" {
+			r.lossy("storage.files[%d].user.name", i)
+		}
+		if oldFile.Group.Name != "" {
+			r.lossy("storage.files[%d].group.name", i)
+		}
+		if oldFile.Contents.Template {
+			r.lossy("storage.files[%d].contents.template", i)
+		}
+		if oldFile.Contents.Compression != "" {
+			r.lossy("storage.files[%d].contents.compression", i)
+		}
+		if len(oldFile.Append) > 0 {
+			r.lossy("storage.files[%d].append", i)
+		}
+		if !oldFile.ShouldOverwrite() {
+			r.lossy("storage.files[%d].overwrite", i)
+		}
+		if len(oldFile.Contents.Verification.AllHashes()) > 0 || oldFile.Contents.Verification.Signature != nil {
+			r.lossy("storage.files[%d].contents.verification", i)
+		}
+
+		file := v1.File{
+			Path: v1.Path(oldFile.Path),
+			Mode: v1.FileMode(oldFile.Mode),
+		}
+		if oldFile.User.ID != nil {
+			file.Uid = *oldFile.User.ID
+		}
+		if oldFile.Group.ID != nil {
+			file.Gid = *oldFile.Group.ID
+		}
+		if oldFile.Contents.Source != "" {
+			// Version 1 only supports inline file contents.
+			contents, err := dataurl.DecodeString(oldFile.Contents.Source)
+			if err != nil {
+				r.lossy("storage.files[%d].contents.source (not a data url)", i)
+			} else {
+				file.Contents = string(contents.Data)
+			}
+		}
+
+		config.Storage.Filesystems[idx].Files = append(config.Storage.Filesystems[idx].Files, file)
+	}
+
+	for i := range cfg.Storage.Directories {
+		r.lossy("storage.directories[%d]", i)
+	}
+
+	for i := range cfg.Storage.Links {
+		r.lossy("storage.links[%d]", i)
+	}
+
+	for i := range cfg.Storage.Remove {
+		r.lossy("storage.remove[%d]", i)
+	}
+
+	for i, oldUnit := range cfg.Systemd.Units {
+		if oldUnit.Template {
+			r.lossy("systemd.units[%d].template", i)
+		}
+
+		unit := v1.SystemdUnit{
+			Name:     v1.SystemdUnitName(oldUnit.Name),
+			Enable:   oldUnit.Enable,
+			Mask:     oldUnit.Mask,
+			Contents: oldUnit.Contents,
+		}
+
+		for _, oldDropin := range oldUnit.Dropins {
+			unit.DropIns = append(unit.DropIns, v1.SystemdUnitDropIn{
+				Name:     v1.SystemdUnitDropInName(oldDropin.Name),
+				Contents: oldDropin.Contents,
+			})
+		}
+
+		config.Systemd.Units = append(config.Systemd.Units, unit)
+	}
+
+	for _, oldUnit := range cfg.Networkd.Units {
+		config.Networkd.Units = append(config.Networkd.Units, v1.NetworkdUnit{
+			Name:     v1.NetworkdUnitName(oldUnit.Name),
+			Contents: oldUnit.Contents,
+		})
+	}
+
+	for i, oldUser := range cfg.Passwd.Users {
+		r.checkUnrepresentableUser(i, oldUser)
+
+		user := v1.User{
+			Name:              oldUser.Name,
+			SSHAuthorizedKeys: translateV2_1SSHAuthorizedKeySliceToStringSlice(oldUser.SSHAuthorizedKeys),
+		}
+		if oldUser.PasswordHash != nil {
+			user.PasswordHash = *oldUser.PasswordHash
+		}
+
+		if oldUser.Create != nil {
+			var uid *uint
+			if oldUser.Create.UID != nil {
+				if *oldUser.Create.UID < 0 {
+					r.lossy("passwd.users[%d].create.uid (negative)", i)
+				}
+				tmp := uint(*oldUser.Create.UID)
+				uid = &tmp
+			}
+			user.Create = &v1.UserCreate{
+				Uid:          uid,
+				GECOS:        oldUser.Create.Gecos,
+				Homedir:      oldUser.Create.HomeDir,
+				NoCreateHome: oldUser.Create.NoCreateHome,
+				PrimaryGroup: oldUser.Create.PrimaryGroup,
+				Groups:       translateV2_1UsercreateGroupSliceToStringSlice(oldUser.Create.Groups),
+				NoUserGroup:  oldUser.Create.NoUserGroup,
+				System:       oldUser.Create.System,
+				NoLogInit:    oldUser.Create.NoLogInit,
+				Shell:        oldUser.Create.Shell,
+			}
+		}
+
+		config.Passwd.Users = append(config.Passwd.Users, user)
+	}
+
+	for i, oldGroup := range cfg.Passwd.Groups {
+		var gid *uint
+		if oldGroup.Gid != nil {
+			if *oldGroup.Gid < 0 {
+				r.lossy("passwd.groups[%d].gid (negative)", i)
+			}
+			tmp := uint(*oldGroup.Gid)
+			gid = &tmp
+		}
+		config.Passwd.Groups = append(config.Passwd.Groups, v1.Group{
+			Name:         oldGroup.Name,
+			Gid:          gid,
+			PasswordHash: oldGroup.PasswordHash,
+			System:       oldGroup.System,
+		})
+	}
+
+	if r.IsFatal() {
+		return v1.Config{}, r.Report, ErrLossyTranslation
+	}
+	return config, r.Report, nil
+}
+
+// golang--
+func translateV2_1OptionSliceToV2_0MkfsOptions(opts []types.CreateOption) v2_0.MkfsOptions {
+	var newOpts v2_0.MkfsOptions
+	for _, o := range opts {
+		newOpts = append(newOpts, string(o))
+	}
+	return newOpts
+}
+
+// golang--
+func translateV2_1SSHAuthorizedKeySliceToStringSlice(keys []types.SSHAuthorizedKey) []string {
+	var newKeys []string
+	for _, k := range keys {
+		newKeys = append(newKeys, string(k))
+	}
+	return newKeys
+}
+
+// golang--
+func translateV2_1UsercreateGroupSliceToStringSlice(groups []types.UsercreateGroup) []string {
+	var newGroups []string
+	for _, g := range groups {
+		newGroups = append(newGroups, string(g))
+	}
+	return newGroups
+}
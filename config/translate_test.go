@@ -24,6 +24,7 @@ import (
 	"github.com/coreos/ignition/config/types"
 	v1 "github.com/coreos/ignition/config/v1/types"
 	v2_0 "github.com/coreos/ignition/config/v2_0/types"
+	v2_1 "github.com/coreos/ignition/config/v2_1/types"
 )
 
 func TestTranslateFromV1(t *testing.T) {
@@ -944,3 +945,675 @@ func TestTranslateFromV2_0(t *testing.T) {
 		assert.Equal(t, config, test.out.config, "#%d: bad config", i)
 	}
 }
+
+func TestTranslateFromV2_1(t *testing.T) {
+	type in struct {
+		config v2_1.Config
+	}
+	type out struct {
+		config types.Config
+	}
+
+	hash := "sha512-0123456789abcdef"
+	label := "ROOT"
+	path := "/sysroot"
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{},
+			out: out{config: types.Config{Ignition: types.Ignition{Version: types.MaxVersion.String()}}},
+		},
+		{
+			in: in{config: v2_1.Config{
+				Ignition: v2_1.Ignition{
+					Config: v2_1.IgnitionConfig{
+						Append: []v2_1.ConfigReference{
+							{Source: "data:,file1"},
+							{
+								Source:       "data:,file2",
+								Verification: v2_1.Verification{Hash: &hash},
+							},
+						},
+						Replace: &v2_1.ConfigReference{
+							Source:       "data:,file3",
+							Verification: v2_1.Verification{Hash: &hash},
+						},
+					},
+					Timeouts: v2_1.Timeouts{
+						HTTPResponseHeaders: intToPtr(5),
+						HTTPTotal:           intToPtr(10),
+					},
+				},
+			}},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{
+					Version: types.MaxVersion.String(),
+					Config: types.IgnitionConfig{
+						Append: []types.ConfigReference{
+							{Source: "data:,file1"},
+							{
+								Source:       "data:,file2",
+								Verification: types.Verification{Hash: &hash},
+							},
+						},
+						Replace: &types.ConfigReference{
+							Source:       "data:,file3",
+							Verification: types.Verification{Hash: &hash},
+						},
+					},
+					Timeouts: types.Timeouts{
+						HTTPResponseHeaders: intToPtr(5),
+						HTTPTotal:           intToPtr(10),
+					},
+				},
+			}},
+		},
+		{
+			in: in{config: v2_1.Config{
+				Ignition: v2_1.Ignition{Version: v2_1.MaxVersion.String()},
+				Storage: v2_1.Storage{
+					Disks: []v2_1.Disk{
+						{
+							Device:    "/dev/sda",
+							WipeTable: true,
+							Partitions: []v2_1.Partition{
+								{
+									Label:    "ROOT",
+									Number:   1,
+									Size:     4096,
+									Start:    2048,
+									TypeGUID: "4F68BCE3-E8CD-4DB1-96E7-FBCAF984B709",
+									GUID:     "5DFBF5F4-2848-4BAC-AA5E-0D9A20B745A6",
+								},
+							},
+						},
+					},
+					Raid: []v2_1.Raid{
+						{
+							Name:    "md0",
+							Level:   "raid1",
+							Devices: []v2_1.Device{"/dev/sdb", "/dev/sdc"},
+							Spares:  1,
+						},
+					},
+					Filesystems: []v2_1.Filesystem{
+						{
+							Name: "data",
+							Mount: &v2_1.Mount{
+								Device:         "/dev/md0",
+								Format:         "ext4",
+								Label:          &label,
+								Options:        []v2_1.MountOption{"-b", "1024"},
+								WipeFilesystem: true,
+							},
+						},
+						{
+							Name: "old",
+							Mount: &v2_1.Mount{
+								Device: "/dev/sdd",
+								Format: "xfs",
+								Create: &v2_1.Create{
+									Force:   true,
+									Options: []v2_1.CreateOption{"-f"},
+								},
+							},
+						},
+						{
+							Name: "root",
+							Path: &path,
+						},
+					},
+					Files: []v2_1.File{
+						{
+							Node: v2_1.Node{
+								Filesystem: "root",
+								Path:       "/etc/motd",
+								User:       v2_1.NodeUser{ID: intToPtr(500)},
+								Group:      v2_1.NodeGroup{Name: "core"},
+							},
+							FileEmbedded1: v2_1.FileEmbedded1{
+								Mode: 0644,
+								Contents: v2_1.FileContents{
+									Compression:  "gzip",
+									Source:       "https://example.com/motd.gz",
+									Verification: v2_1.Verification{Hash: &hash},
+								},
+							},
+						},
+					},
+					Directories: []v2_1.Directory{
+						{
+							Node:               v2_1.Node{Filesystem: "data", Path: "/srv"},
+							DirectoryEmbedded1: v2_1.DirectoryEmbedded1{Mode: 0755},
+						},
+					},
+					Links: []v2_1.Link{
+						{
+							Node:          v2_1.Node{Filesystem: "root", Path: "/etc/localtime"},
+							LinkEmbedded1: v2_1.LinkEmbedded1{Target: "/usr/share/zoneinfo/UTC"},
+						},
+						{
+							Node:          v2_1.Node{Filesystem: "root", Path: "/etc/hostname.bak"},
+							LinkEmbedded1: v2_1.LinkEmbedded1{Hard: true, Target: "/etc/hostname"},
+						},
+					},
+				},
+			}},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device:    "/dev/sda",
+							WipeTable: true,
+							Partitions: []types.Partition{
+								{
+									Label:    "ROOT",
+									Number:   1,
+									Size:     4096,
+									Start:    2048,
+									TypeGUID: "4F68BCE3-E8CD-4DB1-96E7-FBCAF984B709",
+									GUID:     "5DFBF5F4-2848-4BAC-AA5E-0D9A20B745A6",
+								},
+							},
+						},
+					},
+					Raid: []types.Raid{
+						{
+							Name:    "md0",
+							Level:   "raid1",
+							Devices: []types.Device{"/dev/sdb", "/dev/sdc"},
+							Spares:  1,
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Name: "data",
+							Mount: &types.Mount{
+								Device:         "/dev/md0",
+								Format:         "ext4",
+								Label:          &label,
+								Options:        []types.MountOption{"-b", "1024"},
+								WipeFilesystem: true,
+							},
+						},
+						{
+							Name: "old",
+							Mount: &types.Mount{
+								Device: "/dev/sdd",
+								Format: "xfs",
+								Create: &types.Create{
+									Force:   true,
+									Options: []types.CreateOption{"-f"},
+								},
+							},
+						},
+						{
+							Name: "root",
+							Path: &path,
+						},
+					},
+					Files: []types.File{
+						{
+							Node: types.Node{
+								Filesystem: "root",
+								Path:       "/etc/motd",
+								User:       types.NodeUser{ID: intToPtr(500)},
+								Group:      types.NodeGroup{Name: "core"},
+							},
+							FileEmbedded1: types.FileEmbedded1{
+								Mode: 0644,
+								Contents: types.FileContents{
+									Compression:  "gzip",
+									Source:       "https://example.com/motd.gz",
+									Verification: types.Verification{Hash: &hash},
+								},
+							},
+						},
+					},
+					Directories: []types.Directory{
+						{
+							Node:               types.Node{Filesystem: "data", Path: "/srv"},
+							DirectoryEmbedded1: types.DirectoryEmbedded1{Mode: 0755},
+						},
+					},
+					Links: []types.Link{
+						{
+							Node:          types.Node{Filesystem: "root", Path: "/etc/localtime"},
+							LinkEmbedded1: types.LinkEmbedded1{Target: "/usr/share/zoneinfo/UTC"},
+						},
+						{
+							Node:          types.Node{Filesystem: "root", Path: "/etc/hostname.bak"},
+							LinkEmbedded1: types.LinkEmbedded1{Hard: true, Target: "/etc/hostname"},
+						},
+					},
+				},
+			}},
+		},
+		{
+			in: in{config: v2_1.Config{
+				Systemd: v2_1.Systemd{
+					Units: []v2_1.Unit{
+						{
+							Name:     "test1.service",
+							Enable:   true,
+							Contents: "test1 contents",
+							Dropins: []v2_1.Dropin{
+								{
+									Name:     "conf1.conf",
+									Contents: "conf1 contents",
+								},
+							},
+						},
+						{
+							Name: "test2.service",
+							Mask: true,
+						},
+					},
+				},
+				Networkd: v2_1.Networkd{
+					Units: []v2_1.Networkdunit{
+						{
+							Name:     "static.network",
+							Contents: "[Match]\nName=eth0",
+						},
+					},
+				},
+				Passwd: v2_1.Passwd{
+					Users: []v2_1.PasswdUser{
+						{
+							Name:              "user 1",
+							PasswordHash:      strToPtr("password 1"),
+							SSHAuthorizedKeys: []v2_1.SSHAuthorizedKey{"key1", "key2"},
+							UID:               intToPtr(1010),
+							Groups:            []v2_1.Group{"wheel"},
+							HomeDir:           "/home/user1",
+							Shell:             "/bin/zsh",
+						},
+						{
+							Name: "user 2",
+							Create: &v2_1.Usercreate{
+								UID:          intToPtr(1020),
+								Gecos:        "User 2",
+								Groups:       []v2_1.UsercreateGroup{"docker"},
+								NoCreateHome: true,
+								System:       true,
+							},
+						},
+					},
+					Groups: []v2_1.PasswdGroup{
+						{
+							Name:         "group 1",
+							Gid:          intToPtr(1000),
+							PasswordHash: "password 1",
+							System:       true,
+						},
+					},
+				},
+			}},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Name:     "test1.service",
+							Enable:   true,
+							Contents: "test1 contents",
+							Dropins: []types.Dropin{
+								{
+									Name:     "conf1.conf",
+									Contents: "conf1 contents",
+								},
+							},
+						},
+						{
+							Name: "test2.service",
+							Mask: true,
+						},
+					},
+				},
+				Networkd: types.Networkd{
+					Units: []types.Networkdunit{
+						{
+							Name:     "static.network",
+							Contents: "[Match]\nName=eth0",
+						},
+					},
+				},
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{
+						{
+							Name:              "user 1",
+							PasswordHash:      strToPtr("password 1"),
+							SSHAuthorizedKeys: []types.SSHAuthorizedKey{"key1", "key2"},
+							UID:               intToPtr(1010),
+							Groups:            []types.Group{"wheel"},
+							HomeDir:           "/home/user1",
+							Shell:             "/bin/zsh",
+						},
+						{
+							Name: "user 2",
+							Create: &types.Usercreate{
+								UID:          intToPtr(1020),
+								Gecos:        "User 2",
+								Groups:       []types.UsercreateGroup{"docker"},
+								NoCreateHome: true,
+								System:       true,
+							},
+						},
+					},
+					Groups: []types.PasswdGroup{
+						{
+							Name:         "group 1",
+							Gid:          intToPtr(1000),
+							PasswordHash: "password 1",
+							System:       true,
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		config := TranslateFromV2_1(test.in.config)
+		assert.Equal(t, test.out.config, config, "#%d: bad config", i)
+	}
+}
+
+func TestTranslateToV2_0(t *testing.T) {
+	type in struct {
+		config types.Config
+	}
+	type out struct {
+		config v2_0.Config
+		err    error
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in: in{config: types.Config{}},
+			out: out{config: v2_0.Config{
+				Ignition: v2_0.Ignition{Version: v2_0.IgnitionVersion(v2_0.MaxVersion)},
+			}},
+		},
+		{
+			in: in{config: types.Config{
+				Ignition: types.Ignition{
+					Config: types.IgnitionConfig{
+						Append: []types.ConfigReference{
+							{
+								Source: "http://example.com/config.ign",
+								Verification: types.Verification{
+									Hash: strToPtr("sha512-0123456789abcdef"),
+								},
+							},
+						},
+					},
+				},
+				Storage: types.Storage{
+					Filesystems: []types.Filesystem{
+						{
+							Name: "filesystem-1",
+							Mount: &types.Mount{
+								Device: "/dev/disk/by-partlabel/ROOT",
+								Format: "btrfs",
+								Create: &types.Create{
+									Force:   true,
+									Options: []types.CreateOption{"-L", "ROOT"},
+								},
+							},
+						},
+					},
+					Files: []types.File{
+						{
+							Node: types.Node{
+								Filesystem: "filesystem-1",
+								Path:       "/opt/file",
+								User:       types.NodeUser{ID: intToPtr(500)},
+								Group:      types.NodeGroup{ID: intToPtr(501)},
+							},
+							FileEmbedded1: types.FileEmbedded1{
+								Mode:     420,
+								Contents: types.FileContents{Source: "data:,file1"},
+							},
+						},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Name:    "test.service",
+							Enable:  true,
+							Dropins: []types.Dropin{{Name: "conf.conf", Contents: "[Service]"}},
+						},
+					},
+				},
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{
+						{
+							Name:              "user 1",
+							PasswordHash:      strToPtr("password 1"),
+							SSHAuthorizedKeys: []types.SSHAuthorizedKey{"key1"},
+							Create: &types.Usercreate{
+								UID:    intToPtr(1010),
+								Groups: []types.UsercreateGroup{"wheel"},
+							},
+						},
+					},
+					Groups: []types.PasswdGroup{{Name: "group 1", Gid: intToPtr(1000)}},
+				},
+			}},
+			out: out{config: v2_0.Config{
+				Ignition: v2_0.Ignition{
+					Version: v2_0.IgnitionVersion(v2_0.MaxVersion),
+					Config: v2_0.IgnitionConfig{
+						Append: []v2_0.ConfigReference{
+							{
+								Source: v2_0.Url{Scheme: "http", Host: "example.com", Path: "/config.ign"},
+								Verification: v2_0.Verification{
+									Hash: &v2_0.Hash{Function: "sha512", Sum: "0123456789abcdef"},
+								},
+							},
+						},
+					},
+				},
+				Storage: v2_0.Storage{
+					Filesystems: []v2_0.Filesystem{
+						{
+							Name: "filesystem-1",
+							Mount: &v2_0.FilesystemMount{
+								Device: v2_0.Path("/dev/disk/by-partlabel/ROOT"),
+								Format: v2_0.FilesystemFormat("btrfs"),
+								Create: &v2_0.FilesystemCreate{
+									Force:   true,
+									Options: v2_0.MkfsOptions([]string{"-L", "ROOT"}),
+								},
+							},
+						},
+					},
+					Files: []v2_0.File{
+						{
+							Filesystem: "filesystem-1",
+							Path:       v2_0.Path("/opt/file"),
+							Mode:       v2_0.FileMode(420),
+							User:       v2_0.FileUser{Id: 500},
+							Group:      v2_0.FileGroup{Id: 501},
+							Contents: v2_0.FileContents{
+								Source: v2_0.Url{Scheme: "data", Opaque: ",file1"},
+							},
+						},
+					},
+				},
+				Systemd: v2_0.Systemd{
+					Units: []v2_0.SystemdUnit{
+						{
+							Name:    v2_0.SystemdUnitName("test.service"),
+							Enable:  true,
+							DropIns: []v2_0.SystemdUnitDropIn{{Name: v2_0.SystemdUnitDropInName("conf.conf"), Contents: "[Service]"}},
+						},
+					},
+				},
+				Passwd: v2_0.Passwd{
+					Users: []v2_0.User{
+						{
+							Name:              "user 1",
+							PasswordHash:      "password 1",
+							SSHAuthorizedKeys: []string{"key1"},
+							Create: &v2_0.UserCreate{
+								Uid:    func(i uint) *uint { return &i }(1010),
+								Groups: []string{"wheel"},
+							},
+						},
+					},
+					Groups: []v2_0.Group{{Name: "group 1", Gid: func(i uint) *uint { return &i }(1000)}},
+				},
+			}},
+		},
+		{
+			in: in{config: types.Config{
+				Ignition: types.Ignition{
+					Timeouts: types.Timeouts{HTTPTotal: intToPtr(10)},
+				},
+				Storage: types.Storage{
+					Directories: []types.Directory{{Node: types.Node{Filesystem: "root", Path: "/opt"}}},
+				},
+				Passwd: types.Passwd{
+					Users: []types.PasswdUser{{Name: "core", Shell: "/bin/zsh"}},
+				},
+			}},
+			out: out{err: ErrLossyTranslation},
+		},
+	}
+
+	for i, test := range tests {
+		config, r, err := TranslateToV2_0(test.in.config)
+		assert.Equal(t, test.out.err, err, "#%d: bad error", i)
+		assert.Equal(t, test.out.config, config, "#%d: bad config", i)
+		if test.out.err != nil {
+			assert.Equal(t, 3, len(r.Entries), "#%d: bad number of report entries", i)
+		}
+	}
+}
+
+func TestTranslateToV1(t *testing.T) {
+	type in struct {
+		config types.Config
+	}
+	type out struct {
+		config v1.Config
+		err    error
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{config: types.Config{}},
+			out: out{config: v1.Config{Version: v1.Version}},
+		},
+		{
+			in: in{config: types.Config{
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device:     "/dev/sda",
+							WipeTable:  true,
+							Partitions: []types.Partition{{Label: "ROOT", Number: 1, Size: 1024}},
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Name: "filesystem-1",
+							Mount: &types.Mount{
+								Device: "/dev/disk/by-partlabel/ROOT",
+								Format: "ext4",
+							},
+						},
+					},
+					Files: []types.File{
+						{
+							Node: types.Node{
+								Filesystem: "filesystem-1",
+								Path:       "/opt/file",
+								User:       types.NodeUser{ID: intToPtr(500)},
+							},
+							FileEmbedded1: types.FileEmbedded1{
+								Mode:     420,
+								Contents: types.FileContents{Source: "data:,file1"},
+							},
+						},
+					},
+				},
+				Networkd: types.Networkd{
+					Units: []types.Networkdunit{{Name: "empty.netdev", Contents: "[Match]"}},
+				},
+			}},
+			out: out{config: v1.Config{
+				Version: v1.Version,
+				Storage: v1.Storage{
+					Disks: []v1.Disk{
+						{
+							Device:     v1.Path("/dev/sda"),
+							WipeTable:  true,
+							Partitions: []v1.Partition{{Label: v1.PartitionLabel("ROOT"), Number: 1, Size: v1.PartitionDimension(1024)}},
+						},
+					},
+					Filesystems: []v1.Filesystem{
+						{
+							Device: v1.Path("/dev/disk/by-partlabel/ROOT"),
+							Format: v1.FilesystemFormat("ext4"),
+							Files: []v1.File{
+								{
+									Path:     v1.Path("/opt/file"),
+									Contents: "file1",
+									Mode:     v1.FileMode(420),
+									Uid:      500,
+								},
+							},
+						},
+					},
+				},
+				Networkd: v1.Networkd{
+					Units: []v1.NetworkdUnit{{Name: v1.NetworkdUnitName("empty.netdev"), Contents: "[Match]"}},
+				},
+			}},
+		},
+		{
+			in: in{config: types.Config{
+				Ignition: types.Ignition{
+					Config: types.IgnitionConfig{
+						Replace: &types.ConfigReference{Source: "http://example.com/config.ign"},
+					},
+				},
+				Storage: types.Storage{
+					Files: []types.File{
+						{
+							Node: types.Node{Filesystem: "root", Path: "/opt/file"},
+							FileEmbedded1: types.FileEmbedded1{
+								Contents: types.FileContents{Source: "http://example.com/file"},
+							},
+						},
+					},
+				},
+			}},
+			out: out{err: ErrLossyTranslation},
+		},
+	}
+
+	for i, test := range tests {
+		config, r, err := TranslateToV1(test.in.config)
+		assert.Equal(t, test.out.err, err, "#%d: bad error", i)
+		assert.Equal(t, test.out.config, config, "#%d: bad config", i)
+		if test.out.err != nil {
+			assert.Equal(t, 2, len(r.Entries), "#%d: bad number of report entries", i)
+		}
+	}
+}
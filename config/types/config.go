@@ -16,6 +16,7 @@ package types
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/coreos/go-semver/semver"
 
@@ -25,16 +26,32 @@ import (
 var (
 	MaxVersion = semver.Version{
 		Major:      2,
-		Minor:      1,
+		Minor:      2,
 		PreRelease: "experimental",
 	}
 )
 
+const (
+	CodeFileNonexistentFilesystem = "file-nonexistent-filesystem"
+	CodeFilesystemShadowed        = "filesystem-shadowed"
+	CodeRaidDeviceUndefined       = "raid-device-undefined"
+	CodeFilesystemDeviceUndefined = "filesystem-device-undefined"
+	CodeNodeUserUndefined         = "node-user-undefined"
+	CodeNodeGroupUndefined        = "node-group-undefined"
+	CodeUnitEnabledUndefined      = "unit-enabled-undefined"
+	CodeDropinMaskedUnit          = "dropin-masked-unit"
+)
+
 func (c Config) Validate() report.Report {
 	r := report.Report{}
 	rules := []rule{
 		checkFilesFilesystems,
 		checkDuplicateFilesystems,
+		checkRaidDevices,
+		checkFilesystemDevices,
+		checkNodeUsersAndGroups,
+		checkEnabledUnitsDefined,
+		checkDropinsUnmasked,
 	}
 
 	for _, rule := range rules {
@@ -50,7 +67,7 @@ func checkFilesFilesystems(cfg Config, r *report.Report) {
 	for _, filesystem := range cfg.Storage.Filesystems {
 		filesystems[filesystem.Name] = struct{}{}
 	}
-	for _, file := range cfg.Storage.Files {
+	for i, file := range cfg.Storage.Files {
 		if file.Filesystem == "" {
 			// Filesystem was not specified. This is an error, but its handled in types.File's Validate, not here
 			continue
@@ -61,6 +78,8 @@ func checkFilesFilesystems(cfg Config, r *report.Report) {
 				Kind: report.EntryWarning,
 				Message: fmt.Sprintf("File %q references nonexistent filesystem %q. (This is ok if it is defined in a referenced config)",
 					file.Path, file.Filesystem),
+				Code: CodeFileNonexistentFilesystem,
+				Path: fmt.Sprintf("/storage/files/%d/filesystem", i),
 			})
 		}
 	}
@@ -68,13 +87,174 @@ func checkFilesFilesystems(cfg Config, r *report.Report) {
 
 func checkDuplicateFilesystems(cfg Config, r *report.Report) {
 	filesystems := map[string]struct{}{"root": {}}
-	for _, filesystem := range cfg.Storage.Filesystems {
+	for i, filesystem := range cfg.Storage.Filesystems {
 		if _, ok := filesystems[filesystem.Name]; ok {
 			r.Add(report.Entry{
 				Kind:    report.EntryWarning,
 				Message: fmt.Sprintf("Filesystem %q shadows exising filesystem definition", filesystem.Name),
+				Code:    CodeFilesystemShadowed,
+				Path:    fmt.Sprintf("/storage/filesystems/%d/name", i),
 			})
 		}
 		filesystems[filesystem.Name] = struct{}{}
 	}
 }
+
+// partitionDevices returns the device paths which may be used to refer to the
+// partitions of the disk.
+func partitionDevices(disk Disk) []string {
+	devices := []string{}
+	for _, p := range disk.Partitions {
+		if p.Label != "" {
+			devices = append(devices, "/dev/disk/by-partlabel/"+p.Label)
+		}
+		if p.GUID != "" {
+			devices = append(devices, "/dev/disk/by-partuuid/"+strings.ToLower(p.GUID))
+		}
+		if p.Number != 0 {
+			// e.g. /dev/sda1, /dev/nvme0n1p1, and /dev/disk/by-id/ata-foo-part1
+			for _, sep := range []string{"", "p", "-part"} {
+				devices = append(devices, fmt.Sprintf("%s%s%d", disk.Device, sep, p.Number))
+			}
+		}
+	}
+	return devices
+}
+
+// diskDevices returns the set of device paths of the disks and partitions
+// which are defined in the config.
+func diskDevices(cfg Config) map[string]struct{} {
+	devices := map[string]struct{}{}
+	for _, disk := range cfg.Storage.Disks {
+		devices[disk.Device] = struct{}{}
+		for _, d := range partitionDevices(disk) {
+			devices[d] = struct{}{}
+		}
+	}
+	return devices
+}
+
+func checkRaidDevices(cfg Config, r *report.Report) {
+	devices := diskDevices(cfg)
+	for i, array := range cfg.Storage.Raid {
+		for j, device := range array.Devices {
+			if _, ok := devices[string(device)]; ok {
+				continue
+			}
+			r.Add(report.Entry{
+				Kind: report.EntryWarning,
+				Message: fmt.Sprintf("Array %q uses device %q which is not a disk or partition defined in the config. (This is ok if it already exists)",
+					array.Name, device),
+				Code: CodeRaidDeviceUndefined,
+				Path: fmt.Sprintf("/storage/raid/%d/devices/%d", i, j),
+			})
+		}
+	}
+}
+
+func checkFilesystemDevices(cfg Config, r *report.Report) {
+	devices := diskDevices(cfg)
+	for _, array := range cfg.Storage.Raid {
+		devices["/dev/md/"+array.Name] = struct{}{}
+	}
+	for i, filesystem := range cfg.Storage.Filesystems {
+		if filesystem.Mount == nil {
+			continue
+		}
+		if _, ok := devices[filesystem.Mount.Device]; ok {
+			continue
+		}
+		// Filesystem labels and UUIDs name existing filesystems rather than
+		// devices, so they can't be checked against the config's disks.
+		if strings.HasPrefix(filesystem.Mount.Device, "/dev/disk/by-label/") ||
+			strings.HasPrefix(filesystem.Mount.Device, "/dev/disk/by-uuid/") {
+			continue
+		}
+		r.Add(report.Entry{
+			Kind: report.EntryWarning,
+			Message: fmt.Sprintf("Filesystem %q is on device %q which is not created by the config. (This is ok if it already exists)",
+				filesystem.Name, filesystem.Mount.Device),
+			Code: CodeFilesystemDeviceUndefined,
+			Path: fmt.Sprintf("/storage/filesystems/%d/mount/device", i),
+		})
+	}
+}
+
+func checkNodeUsersAndGroups(cfg Config, r *report.Report) {
+	users := map[string]struct{}{}
+	for _, user := range cfg.Passwd.Users {
+		users[user.Name] = struct{}{}
+	}
+	groups := map[string]struct{}{}
+	for _, group := range cfg.Passwd.Groups {
+		groups[group.Name] = struct{}{}
+	}
+
+	check := func(node Node, path string) {
+		if _, ok := users[node.User.Name]; node.User.Name != "" && !ok {
+			r.Add(report.Entry{
+				Kind:    report.EntryInfo,
+				Message: fmt.Sprintf("%q is owned by user %q which is not defined in passwd. (This is ok if it is provided by the OS)", node.Path, node.User.Name),
+				Code:    CodeNodeUserUndefined,
+				Path:    path + "/user/name",
+			})
+		}
+		if _, ok := groups[node.Group.Name]; node.Group.Name != "" && !ok {
+			r.Add(report.Entry{
+				Kind:    report.EntryInfo,
+				Message: fmt.Sprintf("%q is owned by group %q which is not defined in passwd. (This is ok if it is provided by the OS)", node.Path, node.Group.Name),
+				Code:    CodeNodeGroupUndefined,
+				Path:    path + "/group/name",
+			})
+		}
+	}
+	for i, file := range cfg.Storage.Files {
+		check(file.Node, fmt.Sprintf("/storage/files/%d", i))
+	}
+	for i, dir := range cfg.Storage.Directories {
+		check(dir.Node, fmt.Sprintf("/storage/directories/%d", i))
+	}
+	for i, link := range cfg.Storage.Links {
+		check(link.Node, fmt.Sprintf("/storage/links/%d", i))
+	}
+}
+
+func checkEnabledUnitsDefined(cfg Config, r *report.Report) {
+	defined := map[string]struct{}{}
+	for _, unit := range cfg.Systemd.Units {
+		if unit.Contents != "" {
+			defined[unit.Name] = struct{}{}
+		}
+	}
+	for i, unit := range cfg.Systemd.Units {
+		if _, ok := defined[unit.Name]; !unit.Enable || ok {
+			continue
+		}
+		r.Add(report.Entry{
+			Kind:    report.EntryWarning,
+			Message: fmt.Sprintf("Unit %q is enabled but never defined. (This is ok if it is provided by the OS)", unit.Name),
+			Code:    CodeUnitEnabledUndefined,
+			Path:    fmt.Sprintf("/systemd/units/%d/enable", i),
+		})
+	}
+}
+
+func checkDropinsUnmasked(cfg Config, r *report.Report) {
+	masked := map[string]struct{}{}
+	for _, unit := range cfg.Systemd.Units {
+		if unit.Mask {
+			masked[unit.Name] = struct{}{}
+		}
+	}
+	for i, unit := range cfg.Systemd.Units {
+		if _, ok := masked[unit.Name]; len(unit.Dropins) == 0 || !ok {
+			continue
+		}
+		r.Add(report.Entry{
+			Kind:    report.EntryWarning,
+			Message: fmt.Sprintf("Unit %q is masked, so its dropins will have no effect", unit.Name),
+			Code:    CodeDropinMaskedUnit,
+			Path:    fmt.Sprintf("/systemd/units/%d/dropins", i),
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	type in struct {
+		cfg Config
+	}
+	type out struct {
+		codes []string
+		paths []string
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{cfg: Config{}},
+			out: out{},
+		},
+		{
+			in: in{cfg: Config{
+				Storage: Storage{
+					Disks: []Disk{
+						{
+							Device: "/dev/sda",
+							Partitions: []Partition{
+								{Label: "DATA", Number: 1},
+								{Number: 2},
+							},
+						},
+					},
+					Raid: []Raid{
+						{
+							Name:    "md0",
+							Level:   "raid1",
+							Devices: []Device{"/dev/disk/by-partlabel/DATA", "/dev/sda2", "/dev/sdb1"},
+						},
+					},
+					Filesystems: []Filesystem{
+						{Name: "data", Mount: &Mount{Device: "/dev/md/md0", Format: "ext4"}},
+						{Name: "disk", Mount: &Mount{Device: "/dev/sda", Format: "ext4"}},
+						{Name: "other", Mount: &Mount{Device: "/dev/sdc", Format: "ext4"}},
+						{Name: "label", Mount: &Mount{Device: "/dev/disk/by-label/ROOT", Format: "ext4"}},
+					},
+				},
+			}},
+			out: out{
+				codes: []string{CodeRaidDeviceUndefined, CodeFilesystemDeviceUndefined},
+				paths: []string{"/storage/raid/0/devices/2", "/storage/filesystems/2/mount/device"},
+			},
+		},
+		{
+			in: in{cfg: Config{
+				Storage: Storage{
+					Files: []File{
+						{Node: Node{Filesystem: "root", Path: "/a", User: NodeUser{Name: "core"}, Group: NodeGroup{Name: "wheel"}}},
+						{Node: Node{Filesystem: "root", Path: "/b", User: NodeUser{Name: "user1"}, Group: NodeGroup{Name: "group1"}}},
+					},
+					Directories: []Directory{
+						{Node: Node{Filesystem: "root", Path: "/c", User: NodeUser{Name: "user2"}}},
+					},
+					Links: []Link{
+						{Node: Node{Filesystem: "root", Path: "/d", Group: NodeGroup{Name: "group2"}}},
+					},
+				},
+				Passwd: Passwd{
+					Users:  []PasswdUser{{Name: "user1"}},
+					Groups: []PasswdGroup{{Name: "group1"}},
+				},
+			}},
+			out: out{
+				codes: []string{CodeNodeUserUndefined, CodeNodeGroupUndefined, CodeNodeUserUndefined, CodeNodeGroupUndefined},
+				paths: []string{"/storage/files/0/user/name", "/storage/files/0/group/name", "/storage/directories/0/user/name", "/storage/links/0/group/name"},
+			},
+		},
+		{
+			in: in{cfg: Config{
+				Systemd: Systemd{
+					Units: []Unit{
+						{Name: "a.service", Enable: true, Contents: "[Service]"},
+						{Name: "b.service", Enable: true},
+						{Name: "c.service", Contents: "[Service]"},
+						{Name: "c.service", Enable: true},
+						{Name: "d.service", Mask: true},
+						{Name: "d.service", Dropins: []Dropin{{Name: "a.conf"}}},
+						{Name: "e.service", Dropins: []Dropin{{Name: "a.conf"}}},
+					},
+				},
+			}},
+			out: out{
+				codes: []string{CodeUnitEnabledUndefined, CodeDropinMaskedUnit},
+				paths: []string{"/systemd/units/1/enable", "/systemd/units/5/dropins"},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.cfg.Validate()
+		var codes, paths []string
+		for _, e := range r.Entries {
+			codes = append(codes, e.Code)
+			paths = append(paths, e.Path)
+		}
+		if !reflect.DeepEqual(test.out.codes, codes) || !reflect.DeepEqual(test.out.paths, paths) {
+			t.Errorf("#%d: bad report: want codes %v at %v, got %+v", i, test.out.codes, test.out.paths, r)
+		}
+	}
+}
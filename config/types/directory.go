@@ -18,11 +18,18 @@ import (
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+// ShouldOverwrite returns whether a node other than a directory already at the
+// path of the directory is replaced. Unless set, it isn't.
+func (d Directory) ShouldOverwrite() bool {
+	return d.Overwrite != nil && *d.Overwrite
+}
+
 func (d Directory) ValidateMode() report.Report {
 	r := report.Report{}
 	if err := validateMode(d.Mode); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    CodeFileIllegalMode,
 			Kind:    report.EntryError,
 		})
 	}
@@ -15,23 +15,43 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+var (
+	ErrDiskDeviceRequired = errors.New("disk device is required")
+)
+
+const (
+	CodeDiskDeviceRequired      = "disk-device-required"
+	CodePartitionNumbersCollide = "partition-numbers-collide"
+	CodePartitionsOverlap       = "partitions-overlap"
+	CodePartitionsMisaligned    = "partitions-misaligned"
+)
+
 func (n Disk) Validate() report.Report {
 	return report.Report{}
 }
 
 func (n Disk) ValidateDevice() report.Report {
+	r := report.Report{}
 	if len(n.Device) == 0 {
-		return report.ReportFromError(fmt.Errorf("disk device is required"), report.EntryError)
-	}
-	if err := validatePath(string(n.Device)); err != nil {
-		return report.ReportFromError(err, report.EntryError)
+		r.Add(report.Entry{
+			Message: ErrDiskDeviceRequired.Error(),
+			Code:    CodeDiskDeviceRequired,
+			Kind:    report.EntryError,
+		})
+	} else if err := validatePath(string(n.Device)); err != nil {
+		r.Add(report.Entry{
+			Message: err.Error(),
+			Code:    CodePathRelative,
+			Kind:    report.EntryError,
+		})
 	}
-	return report.Report{}
+	return r
 }
 
 func (n Disk) ValidatePartitions() report.Report {
@@ -39,18 +59,21 @@ func (n Disk) ValidatePartitions() report.Report {
 	if n.partitionNumbersCollide() {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("disk %q: partition numbers collide", n.Device),
+			Code:    CodePartitionNumbersCollide,
 			Kind:    report.EntryError,
 		})
 	}
 	if n.partitionsOverlap() {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("disk %q: partitions overlap", n.Device),
+			Code:    CodePartitionsOverlap,
 			Kind:    report.EntryError,
 		})
 	}
 	if n.partitionsMisaligned() {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("disk %q: partitions misaligned", n.Device),
+			Code:    CodePartitionsMisaligned,
 			Kind:    report.EntryError,
 		})
 	}
@@ -25,24 +25,36 @@ var (
 	ErrCompressionInvalid = errors.New("invalid compression method")
 )
 
+const (
+	CodeCompressionInvalid = "compression-invalid"
+)
+
 func (f File) ValidateMode() report.Report {
 	r := report.Report{}
 	if err := validateMode(f.Mode); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    CodeFileIllegalMode,
 			Kind:    report.EntryError,
 		})
 	}
 	return r
 }
 
+// ShouldOverwrite returns whether a different node already at the path of the
+// file is replaced. Unless set, it is.
+func (f File) ShouldOverwrite() bool {
+	return f.Overwrite == nil || *f.Overwrite
+}
+
 func (fc FileContents) ValidateCompression() report.Report {
 	r := report.Report{}
 	switch fc.Compression {
-	case "", "gzip":
+	case "", "gzip", "bzip2", "xz", "zstd":
 	default:
 		r.Add(report.Entry{
 			Message: ErrCompressionInvalid.Error(),
+			Code:    CodeCompressionInvalid,
 			Kind:    report.EntryError,
 		})
 	}
@@ -55,6 +67,7 @@ func (fc FileContents) ValidateSource() report.Report {
 	if err != nil {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("invalid url %q: %v", fc.Source, err),
+			Code:    urlErrorCode(err),
 			Kind:    report.EntryError,
 		})
 	}
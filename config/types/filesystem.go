@@ -17,6 +17,7 @@ package types
 import (
 	"errors"
 	"fmt"
+	"path"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
@@ -28,6 +29,19 @@ var (
 	ErrUsedCreateAndMountOpts      = errors.New("cannot use both create object and mount-level options field")
 	ErrUsedCreateAndWipeFilesystem = errors.New("cannot use both create object and wipeFilesystem field")
 	ErrWarningCreateDeprecated     = errors.New("the create object has been deprecated in favor of mount-level options")
+	ErrMountPathSwap               = errors.New("swap filesystems cannot be mounted at a path")
+	ErrMountPathRoot               = errors.New("filesystems cannot be mounted over the root filesystem")
+)
+
+const (
+	CodeFilesystemInvalidFormat     = "filesystem-invalid-format"
+	CodeFilesystemNoMountPath       = "filesystem-no-mount-path"
+	CodeFilesystemMountAndPath      = "filesystem-mount-and-path"
+	CodeUsedCreateAndMountOpts      = "used-create-and-mount-opts"
+	CodeUsedCreateAndWipeFilesystem = "used-create-and-wipe-filesystem"
+	CodeWarningCreateDeprecated     = "filesystem-create-deprecated"
+	CodeMountPathSwap               = "mount-path-swap"
+	CodeMountPathRoot               = "mount-path-root"
 )
 
 func (f Filesystem) Validate() report.Report {
@@ -35,6 +49,7 @@ func (f Filesystem) Validate() report.Report {
 	if f.Mount == nil && f.Path == nil {
 		r.Add(report.Entry{
 			Message: ErrFilesystemNoMountPath.Error(),
+			Code:    CodeFilesystemNoMountPath,
 			Kind:    report.EntryError,
 		})
 	}
@@ -42,6 +57,7 @@ func (f Filesystem) Validate() report.Report {
 		if f.Path != nil {
 			r.Add(report.Entry{
 				Message: ErrFilesystemMountAndPath.Error(),
+				Code:    CodeFilesystemMountAndPath,
 				Kind:    report.EntryError,
 			})
 		}
@@ -49,17 +65,20 @@ func (f Filesystem) Validate() report.Report {
 			if f.Mount.WipeFilesystem {
 				r.Add(report.Entry{
 					Message: ErrUsedCreateAndWipeFilesystem.Error(),
+					Code:    CodeUsedCreateAndWipeFilesystem,
 					Kind:    report.EntryError,
 				})
 			}
 			if len(f.Mount.Options) > 0 {
 				r.Add(report.Entry{
 					Message: ErrUsedCreateAndMountOpts.Error(),
+					Code:    CodeUsedCreateAndMountOpts,
 					Kind:    report.EntryError,
 				})
 			}
 			r.Add(report.Entry{
 				Message: ErrWarningCreateDeprecated.Error(),
+				Code:    CodeWarningCreateDeprecated,
 				Kind:    report.EntryWarning,
 			})
 		}
@@ -72,6 +91,7 @@ func (f Filesystem) ValidatePath() report.Report {
 	if f.Path != nil && validatePath(*f.Path) != nil {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("filesystem %q: path not absolute", f.Name),
+			Code:    CodePathRelative,
 			Kind:    report.EntryError,
 		})
 	}
@@ -85,6 +105,32 @@ func (m Mount) Validate() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: ErrFilesystemInvalidFormat.Error(),
+			Code:    CodeFilesystemInvalidFormat,
+			Kind:    report.EntryError,
+		})
+	}
+	if m.Format == "swap" && m.Path != nil {
+		r.Add(report.Entry{
+			Message: ErrMountPathSwap.Error(),
+			Code:    CodeMountPathSwap,
+			Kind:    report.EntryError,
+		})
+	}
+	return r
+}
+
+func (m Mount) ValidatePath() report.Report {
+	r := report.Report{}
+	if m.Path != nil && validatePath(*m.Path) != nil {
+		r.Add(report.Entry{
+			Message: fmt.Sprintf("mount of %q: path not absolute", m.Device),
+			Code:    CodePathRelative,
+			Kind:    report.EntryError,
+		})
+	} else if m.Path != nil && path.Clean(*m.Path) == "/" {
+		r.Add(report.Entry{
+			Message: ErrMountPathRoot.Error(),
+			Code:    CodeMountPathRoot,
 			Kind:    report.EntryError,
 		})
 	}
@@ -96,6 +142,7 @@ func (m Mount) ValidateDevice() report.Report {
 	if err := validatePath(m.Device); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    CodePathRelative,
 			Kind:    report.EntryError,
 		})
 	}
@@ -24,11 +24,15 @@ import (
 func TestMountValidate(t *testing.T) {
 	type in struct {
 		format string
+		path   *string
 	}
 	type out struct {
-		err error
+		err  error
+		code string
 	}
 
+	path := "/var"
+
 	tests := []struct {
 		in  in
 		out out
@@ -43,24 +47,75 @@ func TestMountValidate(t *testing.T) {
 		},
 		{
 			in:  in{format: ""},
-			out: out{err: ErrFilesystemInvalidFormat},
+			out: out{err: ErrFilesystemInvalidFormat, code: CodeFilesystemInvalidFormat},
+		},
+		{
+			in:  in{format: "xfs", path: &path},
+			out: out{},
+		},
+		{
+			in:  in{format: "swap", path: &path},
+			out: out{err: ErrMountPathSwap, code: CodeMountPathSwap},
 		},
 	}
 
 	for i, test := range tests {
-		err := Mount{Format: test.in.format, Device: "/"}.Validate()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		err := Mount{Format: test.in.format, Device: "/", Path: test.in.path}.Validate()
+		if !reflect.DeepEqual(reportFromError(test.out.err, test.out.code), err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
 }
 
+func TestMountValidatePath(t *testing.T) {
+	type in struct {
+		path string
+	}
+	type out struct {
+		report report.Report
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{path: "/var"},
+			out: out{report: report.Report{}},
+		},
+		{
+			in: in{path: "var"},
+			out: out{report: report.Report{Entries: []report.Entry{{
+				Message: `mount of "/dev/sda1": path not absolute`,
+				Code:    CodePathRelative,
+				Kind:    report.EntryError,
+			}}}},
+		},
+		{
+			in:  in{path: "/"},
+			out: out{report: reportFromError(ErrMountPathRoot, CodeMountPathRoot)},
+		},
+		{
+			in:  in{path: "/var/.."},
+			out: out{report: reportFromError(ErrMountPathRoot, CodeMountPathRoot)},
+		},
+	}
+
+	for i, test := range tests {
+		r := Mount{Device: "/dev/sda1", Format: "xfs", Path: &test.in.path}.ValidatePath()
+		if !reflect.DeepEqual(test.out.report, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, test.out.report, r)
+		}
+	}
+}
+
 func TestFilesystemValidate(t *testing.T) {
 	type in struct {
 		filesystem Filesystem
 	}
 	type out struct {
-		err error
+		err  error
+		code string
 	}
 
 	tests := []struct {
@@ -77,17 +132,17 @@ func TestFilesystemValidate(t *testing.T) {
 		},
 		{
 			in:  in{filesystem: Filesystem{Path: func(p string) *string { return &p }("/mount"), Mount: &Mount{Device: "/foo", Format: "ext4"}}},
-			out: out{err: ErrFilesystemMountAndPath},
+			out: out{err: ErrFilesystemMountAndPath, code: CodeFilesystemMountAndPath},
 		},
 		{
 			in:  in{filesystem: Filesystem{}},
-			out: out{err: ErrFilesystemNoMountPath},
+			out: out{err: ErrFilesystemNoMountPath, code: CodeFilesystemNoMountPath},
 		},
 	}
 
 	for i, test := range tests {
 		err := test.in.filesystem.Validate()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		if !reflect.DeepEqual(reportFromError(test.out.err, test.out.code), err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
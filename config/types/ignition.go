@@ -28,12 +28,19 @@ var (
 	ErrInvalidVersion = errors.New("invalid config version (couldn't parse)")
 )
 
+const (
+	CodeOldVersion     = "old-version"
+	CodeNewVersion     = "new-version"
+	CodeInvalidVersion = "invalid-version"
+)
+
 func (c ConfigReference) ValidateSource() report.Report {
 	r := report.Report{}
 	err := validateURL(c.Source)
 	if err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    urlErrorCode(err),
 			Kind:    report.EntryError,
 		})
 	}
@@ -45,15 +52,21 @@ func (v Ignition) Semver() (*semver.Version, error) {
 }
 
 func (v Ignition) Validate() report.Report {
+	r := report.Report{}
+	addErr := func(err error, code string) {
+		r.Add(report.Entry{
+			Message: err.Error(),
+			Code:    code,
+			Kind:    report.EntryError,
+		})
+	}
 	tv, err := v.Semver()
 	if err != nil {
-		return report.ReportFromError(ErrInvalidVersion, report.EntryError)
-	}
-	if MaxVersion.Major > tv.Major {
-		return report.ReportFromError(ErrOldVersion, report.EntryError)
+		addErr(ErrInvalidVersion, CodeInvalidVersion)
+	} else if MaxVersion.Major > tv.Major {
+		addErr(ErrOldVersion, CodeOldVersion)
+	} else if MaxVersion.LessThan(*tv) {
+		addErr(ErrNewVersion, CodeNewVersion)
 	}
-	if MaxVersion.LessThan(*tv) {
-		return report.ReportFromError(ErrNewVersion, report.EntryError)
-	}
-	return report.Report{}
+	return r
 }
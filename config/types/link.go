@@ -20,16 +20,24 @@ import (
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+// Validate checks that the target is absolute. The targets of hard links are
+// paths within the filesystem of the link, so relative ones could only be
+// resolved against the working directory of Ignition.
 func (s Link) Validate() report.Report {
 	r := report.Report{}
-	if !s.Hard {
-		err := validatePath(s.Target)
-		if err != nil {
-			r.Add(report.Entry{
-				Message: fmt.Sprintf("problem with target path %q: %v", s.Target, err),
-				Kind:    report.EntryError,
-			})
-		}
+	err := validatePath(s.Target)
+	if err != nil {
+		r.Add(report.Entry{
+			Message: fmt.Sprintf("problem with target path %q: %v", s.Target, err),
+			Code:    CodePathRelative,
+			Kind:    report.EntryError,
+		})
 	}
 	return r
 }
+
+// ShouldOverwrite returns whether a different node already at the path of the
+// link is replaced. Unless set, it isn't.
+func (s Link) ShouldOverwrite() bool {
+	return s.Overwrite != nil && *s.Overwrite
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestLinkValidate(t *testing.T) {
+	tests := []struct {
+		in  Link
+		out report.Report
+	}{
+		{
+			in:  Link{LinkEmbedded1: LinkEmbedded1{Target: "/etc/a"}},
+			out: report.Report{},
+		},
+		{
+			in:  Link{LinkEmbedded1: LinkEmbedded1{Target: "/etc/a", Hard: true}},
+			out: report.Report{},
+		},
+		{
+			in: Link{LinkEmbedded1: LinkEmbedded1{Target: "a"}},
+			out: report.Report{Entries: []report.Entry{{
+				Message: `problem with target path "a": path not absolute`,
+				Code:    CodePathRelative,
+				Kind:    report.EntryError,
+			}}},
+		},
+		{
+			in: Link{LinkEmbedded1: LinkEmbedded1{Target: "../a", Hard: true}},
+			out: report.Report{Entries: []report.Entry{{
+				Message: `problem with target path "../a": path not absolute`,
+				Code:    CodePathRelative,
+				Kind:    report.EntryError,
+			}}},
+		},
+	}
+
+	for i, test := range tests {
+		if r := test.in.Validate(); !reflect.DeepEqual(test.out, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, test.out, r)
+		}
+	}
+}
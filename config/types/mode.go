@@ -22,6 +22,10 @@ var (
 	ErrFileIllegalMode = errors.New("illegal file mode")
 )
 
+const (
+	CodeFileIllegalMode = "file-illegal-mode"
+)
+
 func validateMode(m int) error {
 	if m < 0 || m > 07777 {
 		return ErrFileIllegalMode
@@ -26,11 +26,17 @@ var (
 	ErrBothIDAndNameSet = errors.New("cannot set both id and name")
 )
 
+const (
+	CodeNoFilesystem     = "no-filesystem"
+	CodeBothIDAndNameSet = "both-id-and-name-set"
+)
+
 func (n Node) ValidateFilesystem() report.Report {
 	r := report.Report{}
 	if n.Filesystem == "" {
 		r.Add(report.Entry{
 			Message: ErrNoFilesystem.Error(),
+			Code:    CodeNoFilesystem,
 			Kind:    report.EntryError,
 		})
 	}
@@ -42,6 +48,7 @@ func (n Node) ValidatePath() report.Report {
 	if err := validatePath(n.Path); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    CodePathRelative,
 			Kind:    report.EntryError,
 		})
 	}
@@ -61,6 +68,7 @@ func (nu NodeUser) Validate() report.Report {
 	if nu.ID != nil && nu.Name != "" {
 		r.Add(report.Entry{
 			Message: ErrBothIDAndNameSet.Error(),
+			Code:    CodeBothIDAndNameSet,
 			Kind:    report.EntryError,
 		})
 	}
@@ -71,6 +79,7 @@ func (ng NodeGroup) Validate() report.Report {
 	if ng.ID != nil && ng.Name != "" {
 		r.Add(report.Entry{
 			Message: ErrBothIDAndNameSet.Error(),
+			Code:    CodeBothIDAndNameSet,
 			Kind:    report.EntryError,
 		})
 	}
@@ -23,7 +23,7 @@ import (
 
 func TestNodeValidatePath(t *testing.T) {
 	node := Node{Path: "not/absolute"}
-	rep := report.ReportFromError(ErrPathRelative, report.EntryError)
+	rep := reportFromError(ErrPathRelative, CodePathRelative)
 	if receivedRep := node.ValidatePath(); !reflect.DeepEqual(rep, receivedRep) {
 		t.Errorf("bad error: want %v, got %v", rep, receivedRep)
 	}
@@ -40,7 +40,7 @@ func TestNodeValidateFilesystem(t *testing.T) {
 		},
 		{
 			node: Node{Path: "/"},
-			r:    report.ReportFromError(ErrNoFilesystem, report.EntryError),
+			r:    reportFromError(ErrNoFilesystem, CodeNoFilesystem),
 		},
 	}
 	for i, test := range tests {
@@ -73,7 +73,7 @@ func TestNodeValidateUser(t *testing.T) {
 		},
 		{
 			in:  NodeUser{intToPtr(1000), "core"},
-			out: report.ReportFromError(ErrBothIDAndNameSet, report.EntryError),
+			out: reportFromError(ErrBothIDAndNameSet, CodeBothIDAndNameSet),
 		},
 	}
 
@@ -104,7 +104,7 @@ func TestNodeValidateGroup(t *testing.T) {
 		},
 		{
 			in:  NodeGroup{intToPtr(1000), "core"},
-			out: report.ReportFromError(ErrBothIDAndNameSet, report.EntryError),
+			out: reportFromError(ErrBothIDAndNameSet, CodeBothIDAndNameSet),
 		},
 	}
 
@@ -115,3 +115,13 @@ func TestNodeValidateGroup(t *testing.T) {
 		}
 	}
 }
+
+// reportFromError returns the report expected from validating a node with a
+// single error.
+func reportFromError(err error, code string) report.Report {
+	r := report.ReportFromError(err, report.EntryError)
+	for i := range r.Entries {
+		r.Entries[i].Code = code
+	}
+	return r
+}
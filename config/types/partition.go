@@ -31,6 +31,11 @@ var (
 	ErrDoesntMatchGUIDRegex = errors.New("doesn't match the form \"01234567-89AB-CDEF-EDCB-A98765432101\"")
 )
 
+const (
+	CodeLabelTooLong         = "partition-label-too-long"
+	CodeDoesntMatchGUIDRegex = "invalid-guid"
+)
+
 func (p Partition) ValidateLabel() report.Report {
 	r := report.Report{}
 	// http://en.wikipedia.org/wiki/GUID_Partition_Table#Partition_entries:
@@ -41,6 +46,7 @@ func (p Partition) ValidateLabel() report.Report {
 	if len(p.Label) > 36 {
 		r.Add(report.Entry{
 			Message: ErrLabelTooLong.Error(),
+			Code:    CodeLabelTooLong,
 			Kind:    report.EntryError,
 		})
 	}
@@ -61,11 +67,13 @@ func validateGUID(guid string) report.Report {
 	if err != nil {
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("error matching guid regexp: %v", err),
+			Code:    CodeDoesntMatchGUIDRegex,
 			Kind:    report.EntryError,
 		})
 	} else if !ok {
 		r.Add(report.Entry{
 			Message: ErrDoesntMatchGUIDRegex.Error(),
+			Code:    CodeDoesntMatchGUIDRegex,
 			Kind:    report.EntryError,
 		})
 	}
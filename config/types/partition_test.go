@@ -46,7 +46,7 @@ func TestValidateLabel(t *testing.T) {
 		},
 		{
 			in{"1111111111111111111111111111111111111"},
-			out{report.ReportFromError(ErrLabelTooLong, report.EntryError)},
+			out{reportFromError(ErrLabelTooLong, CodeLabelTooLong)},
 		},
 	}
 	for i, test := range tests {
@@ -78,7 +78,7 @@ func TestValidateTypeGUID(t *testing.T) {
 		},
 		{
 			in{"not-a-valid-typeguid"},
-			out{report.ReportFromError(ErrDoesntMatchGUIDRegex, report.EntryError)},
+			out{reportFromError(ErrDoesntMatchGUIDRegex, CodeDoesntMatchGUIDRegex)},
 		},
 	}
 	for i, test := range tests {
@@ -110,7 +110,7 @@ func TestValidateGUID(t *testing.T) {
 		},
 		{
 			in{"not-a-valid-typeguid"},
-			out{report.ReportFromError(ErrDoesntMatchGUIDRegex, report.EntryError)},
+			out{reportFromError(ErrDoesntMatchGUIDRegex, CodeDoesntMatchGUIDRegex)},
 		},
 	}
 	for i, test := range tests {
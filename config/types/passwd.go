@@ -34,48 +34,64 @@ var (
 	ErrPasswdCreateAndUID          = errors.New("cannot use both the create object and the user-level uid field")
 )
 
+const (
+	CodePasswdCreateDeprecated      = "passwd-create-deprecated"
+	CodePasswdCreateAndGecos        = "passwd-create-and-gecos"
+	CodePasswdCreateAndGroups       = "passwd-create-and-groups"
+	CodePasswdCreateAndHomeDir      = "passwd-create-and-home-dir"
+	CodePasswdCreateAndNoCreateHome = "passwd-create-and-no-create-home"
+	CodePasswdCreateAndNoLogInit    = "passwd-create-and-no-log-init"
+	CodePasswdCreateAndNoUserGroup  = "passwd-create-and-no-user-group"
+	CodePasswdCreateAndPrimaryGroup = "passwd-create-and-primary-group"
+	CodePasswdCreateAndShell        = "passwd-create-and-shell"
+	CodePasswdCreateAndSystem       = "passwd-create-and-system"
+	CodePasswdCreateAndUID          = "passwd-create-and-uid"
+)
+
 func (p PasswdUser) Validate() report.Report {
 	r := report.Report{}
 	if p.Create != nil {
 		r.Add(report.Entry{
 			Message: ErrPasswdCreateDeprecated.Error(),
+			Code:    CodePasswdCreateDeprecated,
 			Kind:    report.EntryWarning,
 		})
-		addErr := func(err error) {
+		addErr := func(err error, code string) {
 			r.Add(report.Entry{
 				Message: err.Error(),
+				Code:    code,
 				Kind:    report.EntryError,
 			})
 		}
 		if p.Gecos != "" {
-			addErr(ErrPasswdCreateAndGecos)
+			addErr(ErrPasswdCreateAndGecos, CodePasswdCreateAndGecos)
 		}
 		if len(p.Groups) > 0 {
-			addErr(ErrPasswdCreateAndGroups)
+			addErr(ErrPasswdCreateAndGroups, CodePasswdCreateAndGroups)
 		}
 		if p.HomeDir != "" {
-			addErr(ErrPasswdCreateAndHomeDir)
+			addErr(ErrPasswdCreateAndHomeDir, CodePasswdCreateAndHomeDir)
 		}
 		if p.NoCreateHome {
-			addErr(ErrPasswdCreateAndNoCreateHome)
+			addErr(ErrPasswdCreateAndNoCreateHome, CodePasswdCreateAndNoCreateHome)
 		}
 		if p.NoLogInit {
-			addErr(ErrPasswdCreateAndNoLogInit)
+			addErr(ErrPasswdCreateAndNoLogInit, CodePasswdCreateAndNoLogInit)
 		}
 		if p.NoUserGroup {
-			addErr(ErrPasswdCreateAndNoUserGroup)
+			addErr(ErrPasswdCreateAndNoUserGroup, CodePasswdCreateAndNoUserGroup)
 		}
 		if p.PrimaryGroup != "" {
-			addErr(ErrPasswdCreateAndPrimaryGroup)
+			addErr(ErrPasswdCreateAndPrimaryGroup, CodePasswdCreateAndPrimaryGroup)
 		}
 		if p.Shell != "" {
-			addErr(ErrPasswdCreateAndShell)
+			addErr(ErrPasswdCreateAndShell, CodePasswdCreateAndShell)
 		}
 		if p.System {
-			addErr(ErrPasswdCreateAndSystem)
+			addErr(ErrPasswdCreateAndSystem, CodePasswdCreateAndSystem)
 		}
 		if p.UID != nil {
-			addErr(ErrPasswdCreateAndUID)
+			addErr(ErrPasswdCreateAndUID, CodePasswdCreateAndUID)
 		}
 	}
 	return r
@@ -23,6 +23,10 @@ var (
 	ErrPathRelative = errors.New("path not absolute")
 )
 
+const (
+	CodePathRelative = "path-relative"
+)
+
 func validatePath(p string) error {
 	if !path.IsAbs(p) {
 		return ErrPathRelative
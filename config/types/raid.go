@@ -20,6 +20,11 @@ import (
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+const (
+	CodeRaidSparesUnsupported = "raid-spares-unsupported"
+	CodeRaidLevelUnrecognized = "raid-level-unrecognized"
+)
+
 func (n Raid) ValidateLevel() report.Report {
 	r := report.Report{}
 	switch n.Level {
@@ -27,6 +32,7 @@ func (n Raid) ValidateLevel() report.Report {
 		if n.Spares != 0 {
 			r.Add(report.Entry{
 				Message: fmt.Sprintf("spares unsupported for %q arrays", n.Level),
+				Code:    CodeRaidSparesUnsupported,
 				Kind:    report.EntryError,
 			})
 		}
@@ -38,6 +44,7 @@ func (n Raid) ValidateLevel() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("unrecognized raid level: %q", n.Level),
+			Code:    CodeRaidLevelUnrecognized,
 			Kind:    report.EntryError,
 		})
 	}
@@ -50,6 +57,7 @@ func (n Raid) ValidateDevices() report.Report {
 		if err := validatePath(string(d)); err != nil {
 			r.Add(report.Entry{
 				Message: fmt.Sprintf("array %q: device path not absolute: %q", n.Name, d),
+				Code:    CodePathRelative,
 				Kind:    report.EntryError,
 			})
 		}
@@ -0,0 +1,60 @@
+// Copyright 2016 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"path"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+var (
+	ErrRemoveRoot = errors.New("cannot remove the root of a filesystem")
+)
+
+const (
+	CodeRemoveRoot = "remove-root"
+)
+
+func (rm Removal) ValidateFilesystem() report.Report {
+	r := report.Report{}
+	if rm.Filesystem == "" {
+		r.Add(report.Entry{
+			Message: ErrNoFilesystem.Error(),
+			Code:    CodeNoFilesystem,
+			Kind:    report.EntryError,
+		})
+	}
+	return r
+}
+
+func (rm Removal) ValidatePath() report.Report {
+	r := report.Report{}
+	if err := validatePath(rm.Path); err != nil {
+		r.Add(report.Entry{
+			Message: err.Error(),
+			Code:    CodePathRelative,
+			Kind:    report.EntryError,
+		})
+	} else if path.Clean(rm.Path) == "/" {
+		r.Add(report.Entry{
+			Message: ErrRemoveRoot.Error(),
+			Code:    CodeRemoveRoot,
+			Kind:    report.EntryError,
+		})
+	}
+	return r
+}
@@ -0,0 +1,52 @@
+// Copyright 2016 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestRemovalValidatePath(t *testing.T) {
+	tests := []struct {
+		in  Removal
+		out report.Report
+	}{
+		{
+			in:  Removal{Filesystem: "root", Path: "/etc/motd.d/10-sample"},
+			out: report.Report{},
+		},
+		{
+			in:  Removal{Filesystem: "root", Path: "etc/motd"},
+			out: reportFromError(ErrPathRelative, CodePathRelative),
+		},
+		{
+			in:  Removal{Filesystem: "root", Path: "/"},
+			out: reportFromError(ErrRemoveRoot, CodeRemoveRoot),
+		},
+		{
+			in:  Removal{Filesystem: "root", Path: "/etc/.."},
+			out: reportFromError(ErrRemoveRoot, CodeRemoveRoot),
+		},
+	}
+
+	for i, test := range tests {
+		if r := test.in.ValidatePath(); !reflect.DeepEqual(test.out, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, test.out, r)
+		}
+	}
+}
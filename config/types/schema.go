@@ -52,12 +52,14 @@ type File struct {
 type FileContents struct {
 	Compression  string       `json:"compression,omitempty"`
 	Source       string       `json:"source,omitempty"`
+	Template     bool         `json:"template,omitempty"`
 	Verification Verification `json:"verification,omitempty"`
 }
 
 type FileEmbedded1 struct {
-	Contents FileContents `json:"contents,omitempty"`
-	Mode     int          `json:"mode,omitempty"`
+	Append   []FileContents `json:"append,omitempty"`
+	Contents FileContents   `json:"contents,omitempty"`
+	Mode     int            `json:"mode,omitempty"`
 }
 
 type Filesystem struct {
@@ -70,6 +72,7 @@ type Group string
 
 type Ignition struct {
 	Config   IgnitionConfig `json:"config,omitempty"`
+	Security Security       `json:"security,omitempty"`
 	Timeouts Timeouts       `json:"timeouts,omitempty"`
 	Version  string         `json:"version,omitempty"`
 }
@@ -94,7 +97,9 @@ type Mount struct {
 	Device         string        `json:"device,omitempty"`
 	Format         string        `json:"format,omitempty"`
 	Label          *string       `json:"label,omitempty"`
+	MountOptions   []MountOption `json:"mountOptions,omitempty"`
 	Options        []MountOption `json:"options,omitempty"`
+	Path           *string       `json:"path,omitempty"`
 	UUID           *string       `json:"uuid,omitempty"`
 	WipeFilesystem bool          `json:"wipeFilesystem,omitempty"`
 }
@@ -113,6 +118,7 @@ type Networkdunit struct {
 type Node struct {
 	Filesystem string    `json:"filesystem,omitempty"`
 	Group      NodeGroup `json:"group,omitempty"`
+	Overwrite  *bool     `json:"overwrite,omitempty"`
 	Path       string    `json:"path,omitempty"`
 	User       NodeUser  `json:"user,omitempty"`
 }
@@ -172,8 +178,22 @@ type Raid struct {
 	Spares  int      `json:"spares,omitempty"`
 }
 
+type Removal struct {
+	Filesystem string `json:"filesystem,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Recursive  bool   `json:"recursive,omitempty"`
+}
+
 type SSHAuthorizedKey string
 
+type Security struct {
+	TrustedKeys []string `json:"trustedKeys,omitempty"`
+}
+
+type Signature struct {
+	Source string `json:"source,omitempty"`
+}
+
 type Storage struct {
 	Directories []Directory  `json:"directories,omitempty"`
 	Disks       []Disk       `json:"disks,omitempty"`
@@ -181,6 +201,7 @@ type Storage struct {
 	Filesystems []Filesystem `json:"filesystems,omitempty"`
 	Links       []Link       `json:"links,omitempty"`
 	Raid        []Raid       `json:"raid,omitempty"`
+	Remove      []Removal    `json:"remove,omitempty"`
 }
 
 type Systemd struct {
@@ -198,6 +219,7 @@ type Unit struct {
 	Enable   bool     `json:"enable,omitempty"`
 	Mask     bool     `json:"mask,omitempty"`
 	Name     string   `json:"name,omitempty"`
+	Template bool     `json:"template,omitempty"`
 }
 
 type Usercreate struct {
@@ -216,5 +238,7 @@ type Usercreate struct {
 type UsercreateGroup string
 
 type Verification struct {
-	Hash *string `json:"hash,omitempty"`
+	Hash      *string    `json:"hash,omitempty"`
+	Hashes    []string   `json:"hashes,omitempty"`
+	Signature *Signature `json:"signature,omitempty"`
 }
@@ -0,0 +1,70 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+var (
+	ErrKeyNotPEM          = errors.New("trusted key is not PEM encoded")
+	ErrKeyTypeUnsupported = errors.New("trusted key must be an RSA or ECDSA public key")
+)
+
+const (
+	CodeKeyInvalid = "key-invalid"
+)
+
+// ParseTrustedKey parses a PEM encoded PKIX public key which may be used to
+// verify signatures.
+func ParseTrustedKey(key string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, ErrKeyNotPEM
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch pub.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return pub, nil
+	default:
+		return nil, ErrKeyTypeUnsupported
+	}
+}
+
+func (s Security) Validate() report.Report {
+	r := report.Report{}
+	for i, key := range s.TrustedKeys {
+		if _, err := ParseTrustedKey(key); err != nil {
+			r.Add(report.Entry{
+				Message: err.Error(),
+				Code:    CodeKeyInvalid,
+				Kind:    report.EntryError,
+				Path:    fmt.Sprintf("/trustedKeys/%d", i),
+			})
+		}
+	}
+	return r
+}
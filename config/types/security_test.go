@@ -0,0 +1,82 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+const (
+	ecdsaKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEqW6IQwzLeJloyJBSTZJohGmsRHvJ
+llTwqBIae8pr/EJiNWQYSbyJBgpp+5OfuSAywc0oKmi4+tYfWgYpdFzSFg==
+-----END PUBLIC KEY-----
+`
+	ed25519Key = `-----BEGIN PUBLIC KEY-----
+MCowBQYDK2VwAyEAka7V2pT7fqFtg5hyGfW/XLj7XZxJxhMKNR6t5OjieUM=
+-----END PUBLIC KEY-----
+`
+)
+
+func TestSecurityValidate(t *testing.T) {
+	type in struct {
+		security Security
+	}
+	type out struct {
+		r report.Report
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{security: Security{}},
+			out: out{},
+		},
+		{
+			in:  in{security: Security{TrustedKeys: []string{ecdsaKey}}},
+			out: out{},
+		},
+		{
+			in: in{security: Security{TrustedKeys: []string{ecdsaKey, "ssh-rsa AAAA"}}},
+			out: out{r: report.Report{Entries: []report.Entry{{
+				Message: ErrKeyNotPEM.Error(),
+				Code:    CodeKeyInvalid,
+				Kind:    report.EntryError,
+				Path:    "/trustedKeys/1",
+			}}}},
+		},
+		{
+			in: in{security: Security{TrustedKeys: []string{ed25519Key}}},
+			out: out{r: report.Report{Entries: []report.Entry{{
+				Message: ErrKeyTypeUnsupported.Error(),
+				Code:    CodeKeyInvalid,
+				Kind:    report.EntryError,
+				Path:    "/trustedKeys/0",
+			}}}},
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.security.Validate()
+		if !reflect.DeepEqual(test.out.r, r) {
+			t.Errorf("#%d: bad report: want %v, got %v", i, test.out.r, r)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+const (
+	CodeNodeDuplicate       = "node-duplicate"
+	CodeNodeTypeConflict    = "node-type-conflict"
+	CodeNodeParentNotDir    = "node-parent-not-directory"
+	CodeNodeParentIsSymlink = "node-parent-is-symlink"
+)
+
+// storageNode is a file, directory, or link of the storage section, along
+// with the information needed to report conflicts with other nodes.
+type storageNode struct {
+	Node
+	kind    string
+	pointer string
+	symlink bool
+}
+
+func (s Storage) storageNodes() []storageNode {
+	nodes := []storageNode{}
+	for i, f := range s.Files {
+		nodes = append(nodes, storageNode{Node: f.Node, kind: "file", pointer: fmt.Sprintf("/files/%d", i)})
+	}
+	for i, d := range s.Directories {
+		nodes = append(nodes, storageNode{Node: d.Node, kind: "directory", pointer: fmt.Sprintf("/directories/%d", i)})
+	}
+	for i, l := range s.Links {
+		nodes = append(nodes, storageNode{Node: l.Node, kind: "link", pointer: fmt.Sprintf("/links/%d", i), symlink: !l.Hard})
+	}
+	return nodes
+}
+
+// Validate checks that the files, directories, and links of each filesystem
+// don't conflict with one another: a path may only be declared once, and
+// every declared parent of a node must be a directory.
+func (s Storage) Validate() report.Report {
+	r := report.Report{}
+
+	filesystems := map[string]map[string]storageNode{}
+	nodes := s.storageNodes()
+	for _, n := range nodes {
+		paths, ok := filesystems[n.Filesystem]
+		if !ok {
+			paths = map[string]storageNode{}
+			filesystems[n.Filesystem] = paths
+		}
+		p := path.Clean(n.Path)
+		if other, ok := paths[p]; ok {
+			if other.kind == n.kind {
+				r.Add(report.Entry{
+					Kind:        report.EntryWarning,
+					Message:     fmt.Sprintf("%s %q in filesystem %q is declared more than once", n.kind, p, n.Filesystem),
+					Code:        CodeNodeDuplicate,
+					Path:        n.pointer,
+					RelatedPath: other.pointer,
+				})
+			} else {
+				r.Add(report.Entry{
+					Kind:        report.EntryError,
+					Message:     fmt.Sprintf("%q in filesystem %q is declared as both a %s and a %s", p, n.Filesystem, other.kind, n.kind),
+					Code:        CodeNodeTypeConflict,
+					Path:        n.pointer,
+					RelatedPath: other.pointer,
+				})
+			}
+			continue
+		}
+		paths[p] = n
+	}
+
+	for _, n := range nodes {
+		paths := filesystems[n.Filesystem]
+		for p := path.Dir(path.Clean(n.Path)); p != "/" && p != "."; p = path.Dir(p) {
+			parent, ok := paths[p]
+			if !ok || parent.kind == "directory" {
+				continue
+			}
+			if parent.symlink {
+				r.Add(report.Entry{
+					Kind:        report.EntryError,
+					Message:     fmt.Sprintf("%s %q in filesystem %q is beneath link %q", n.kind, n.Path, n.Filesystem, p),
+					Code:        CodeNodeParentIsSymlink,
+					Path:        n.pointer,
+					RelatedPath: parent.pointer,
+				})
+			} else {
+				r.Add(report.Entry{
+					Kind:        report.EntryError,
+					Message:     fmt.Sprintf("%s %q in filesystem %q is beneath %s %q, which is not a directory", n.kind, n.Path, n.Filesystem, parent.kind, p),
+					Code:        CodeNodeParentNotDir,
+					Path:        n.pointer,
+					RelatedPath: parent.pointer,
+				})
+			}
+			// Only report the closest conflicting parent
+			break
+		}
+	}
+
+	return r
+}
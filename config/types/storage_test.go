@@ -0,0 +1,109 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestStorageValidate(t *testing.T) {
+	type in struct {
+		storage Storage
+	}
+	type out struct {
+		entries []report.Entry
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{storage: Storage{}},
+			out: out{},
+		},
+		{
+			in: in{storage: Storage{
+				Files:       []File{{Node: Node{Filesystem: "root", Path: "/etc/foo/bar"}}},
+				Directories: []Directory{{Node: Node{Filesystem: "root", Path: "/etc/foo"}}},
+				Links:       []Link{{Node: Node{Filesystem: "oem", Path: "/etc/foo"}, LinkEmbedded1: LinkEmbedded1{Target: "/bar"}}},
+			}},
+			out: out{},
+		},
+		{
+			in: in{storage: Storage{
+				Files: []File{
+					{Node: Node{Filesystem: "root", Path: "/etc/foo"}},
+					{Node: Node{Filesystem: "root", Path: "/etc/foo/"}},
+				},
+				Directories: []Directory{{Node: Node{Filesystem: "root", Path: "/etc/foo"}}},
+			}},
+			out: out{entries: []report.Entry{
+				{
+					Kind:        report.EntryWarning,
+					Message:     `file "/etc/foo" in filesystem "root" is declared more than once`,
+					Code:        CodeNodeDuplicate,
+					Path:        "/files/1",
+					RelatedPath: "/files/0",
+				},
+				{
+					Kind:        report.EntryError,
+					Message:     `"/etc/foo" in filesystem "root" is declared as both a file and a directory`,
+					Code:        CodeNodeTypeConflict,
+					Path:        "/directories/0",
+					RelatedPath: "/files/0",
+				},
+			}},
+		},
+		{
+			in: in{storage: Storage{
+				Files: []File{
+					{Node: Node{Filesystem: "root", Path: "/etc/foo"}},
+					{Node: Node{Filesystem: "root", Path: "/etc/foo/bar/baz"}},
+					{Node: Node{Filesystem: "root", Path: "/opt/bin/tool"}},
+				},
+				Links: []Link{
+					{Node: Node{Filesystem: "root", Path: "/opt"}, LinkEmbedded1: LinkEmbedded1{Target: "/usr/share/oem"}},
+				},
+			}},
+			out: out{entries: []report.Entry{
+				{
+					Kind:        report.EntryError,
+					Message:     `file "/etc/foo/bar/baz" in filesystem "root" is beneath file "/etc/foo", which is not a directory`,
+					Code:        CodeNodeParentNotDir,
+					Path:        "/files/1",
+					RelatedPath: "/files/0",
+				},
+				{
+					Kind:        report.EntryError,
+					Message:     `file "/opt/bin/tool" in filesystem "root" is beneath link "/opt"`,
+					Code:        CodeNodeParentIsSymlink,
+					Path:        "/files/2",
+					RelatedPath: "/links/0",
+				},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		r := test.in.storage.Validate()
+		if !reflect.DeepEqual(report.Report{Entries: test.out.entries}, r) {
+			t.Errorf("#%d: bad report: want %+v, got %+v", i, test.out.entries, r)
+		}
+	}
+}
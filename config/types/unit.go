@@ -30,11 +30,19 @@ var (
 	ErrInvalidNetworkdExt = errors.New("invalid networkd unit extension")
 )
 
+const (
+	CodeInvalidSystemdExt       = "invalid-systemd-ext"
+	CodeInvalidNetworkdExt      = "invalid-networkd-ext"
+	CodeInvalidSystemdDropinExt = "invalid-systemd-dropin-ext"
+	CodeInvalidUnitContents     = "invalid-unit-contents"
+)
+
 func (u Unit) ValidateContents() report.Report {
 	r := report.Report{}
 	if err := validateUnitContent(u.Contents); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    CodeInvalidUnitContents,
 			Kind:    report.EntryError,
 		})
 	}
@@ -48,6 +56,7 @@ func (u Unit) ValidateName() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: ErrInvalidSystemdExt.Error(),
+			Code:    CodeInvalidSystemdExt,
 			Kind:    report.EntryError,
 		})
 	}
@@ -60,6 +69,7 @@ func (d Dropin) Validate() report.Report {
 	if err := validateUnitContent(d.Contents); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    CodeInvalidUnitContents,
 			Kind:    report.EntryError,
 		})
 	}
@@ -69,6 +79,7 @@ func (d Dropin) Validate() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: fmt.Sprintf("invalid systemd unit drop-in extension: %q", path.Ext(d.Name)),
+			Code:    CodeInvalidSystemdDropinExt,
 			Kind:    report.EntryError,
 		})
 	}
@@ -82,6 +93,7 @@ func (u Networkdunit) Validate() report.Report {
 	if err := validateUnitContent(u.Contents); err != nil {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    CodeInvalidUnitContents,
 			Kind:    report.EntryError,
 		})
 	}
@@ -91,6 +103,7 @@ func (u Networkdunit) Validate() report.Report {
 	default:
 		r.Add(report.Entry{
 			Message: ErrInvalidNetworkdExt.Error(),
+			Code:    CodeInvalidNetworkdExt,
 			Kind:    report.EntryError,
 		})
 	}
@@ -18,8 +18,6 @@ import (
 	"errors"
 	"reflect"
 	"testing"
-
-	"github.com/coreos/ignition/config/validate/report"
 )
 
 func TestSystemdUnitValidateContents(t *testing.T) {
@@ -27,7 +25,8 @@ func TestSystemdUnitValidateContents(t *testing.T) {
 		unit Unit
 	}
 	type out struct {
-		err error
+		err  error
+		code string
 	}
 
 	tests := []struct {
@@ -40,7 +39,7 @@ func TestSystemdUnitValidateContents(t *testing.T) {
 		},
 		{
 			in:  in{unit: Unit{Name: "test.service", Contents: "[Foo"}},
-			out: out{err: errors.New("invalid unit content: unable to find end of section")},
+			out: out{err: errors.New("invalid unit content: unable to find end of section"), code: CodeInvalidUnitContents},
 		},
 		{
 			in:  in{unit: Unit{Name: "test.service", Contents: "", Dropins: []Dropin{{}}}},
@@ -50,7 +49,7 @@ func TestSystemdUnitValidateContents(t *testing.T) {
 
 	for i, test := range tests {
 		err := test.in.unit.ValidateContents()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		if !reflect.DeepEqual(reportFromError(test.out.err, test.out.code), err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
@@ -61,7 +60,8 @@ func TestSystemdUnitValidateName(t *testing.T) {
 		unit string
 	}
 	type out struct {
-		err error
+		err  error
+		code string
 	}
 
 	tests := []struct {
@@ -78,13 +78,13 @@ func TestSystemdUnitValidateName(t *testing.T) {
 		},
 		{
 			in:  in{unit: "test.blah"},
-			out: out{err: ErrInvalidSystemdExt},
+			out: out{err: ErrInvalidSystemdExt, code: CodeInvalidSystemdExt},
 		},
 	}
 
 	for i, test := range tests {
 		err := Unit{Name: test.in.unit, Contents: "[Foo]\nQux=Bar"}.ValidateName()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		if !reflect.DeepEqual(reportFromError(test.out.err, test.out.code), err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
@@ -95,7 +95,8 @@ func TestSystemdUnitDropInValidate(t *testing.T) {
 		unit Dropin
 	}
 	type out struct {
-		err error
+		err  error
+		code string
 	}
 
 	tests := []struct {
@@ -108,13 +109,13 @@ func TestSystemdUnitDropInValidate(t *testing.T) {
 		},
 		{
 			in:  in{unit: Dropin{Name: "test.conf", Contents: "[Foo"}},
-			out: out{err: errors.New("invalid unit content: unable to find end of section")},
+			out: out{err: errors.New("invalid unit content: unable to find end of section"), code: CodeInvalidUnitContents},
 		},
 	}
 
 	for i, test := range tests {
 		err := test.in.unit.Validate()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		if !reflect.DeepEqual(reportFromError(test.out.err, test.out.code), err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
@@ -125,7 +126,8 @@ func TestNetworkdUnitNameValidate(t *testing.T) {
 		unit string
 	}
 	type out struct {
-		err error
+		err  error
+		code string
 	}
 
 	tests := []struct {
@@ -146,13 +148,13 @@ func TestNetworkdUnitNameValidate(t *testing.T) {
 		},
 		{
 			in:  in{unit: "test.blah"},
-			out: out{err: ErrInvalidNetworkdExt},
+			out: out{err: ErrInvalidNetworkdExt, code: CodeInvalidNetworkdExt},
 		},
 	}
 
 	for i, test := range tests {
 		err := Networkdunit{Name: test.in.unit, Contents: "[Foo]\nQux=Bar"}.Validate()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		if !reflect.DeepEqual(reportFromError(test.out.err, test.out.code), err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
@@ -163,7 +165,8 @@ func TestNetworkdUnitValidate(t *testing.T) {
 		unit Networkdunit
 	}
 	type out struct {
-		err error
+		err  error
+		code string
 	}
 
 	tests := []struct {
@@ -176,13 +179,13 @@ func TestNetworkdUnitValidate(t *testing.T) {
 		},
 		{
 			in:  in{unit: Networkdunit{Name: "test.network", Contents: "[Foo"}},
-			out: out{err: errors.New("invalid unit content: unable to find end of section")},
+			out: out{err: errors.New("invalid unit content: unable to find end of section"), code: CodeInvalidUnitContents},
 		},
 	}
 
 	for i, test := range tests {
 		err := test.in.unit.Validate()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		if !reflect.DeepEqual(reportFromError(test.out.err, test.out.code), err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
@@ -25,6 +25,19 @@ var (
 	ErrInvalidScheme = errors.New("invalid url scheme")
 )
 
+const (
+	CodeInvalidScheme = "invalid-url-scheme"
+	CodeInvalidURL    = "invalid-url"
+)
+
+// urlErrorCode returns the code for an error returned by validateURL.
+func urlErrorCode(err error) string {
+	if err == ErrInvalidScheme {
+		return CodeInvalidScheme
+	}
+	return CodeInvalidURL
+}
+
 func validateURL(s string) error {
 	// Empty url is valid, indicates an empty file
 	if s == "" {
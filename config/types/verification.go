@@ -18,6 +18,7 @@ import (
 	"crypto"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/coreos/ignition/config/validate/report"
@@ -29,6 +30,52 @@ var (
 	ErrHashUnrecognized = errors.New("unrecognized hash function")
 )
 
+const (
+	CodeHashMalformed    = "hash-malformed"
+	CodeHashWrongSize    = "hash-wrong-size"
+	CodeHashUnrecognized = "hash-unrecognized"
+)
+
+func (s Signature) ValidateSource() report.Report {
+	r := report.Report{}
+	err := validateURL(s.Source)
+	if err != nil {
+		r.Add(report.Entry{
+			Message: err.Error(),
+			Code:    urlErrorCode(err),
+			Kind:    report.EntryError,
+		})
+	}
+	return r
+}
+
+// hashFunctions are the supported hash functions, by the name used in hash
+// specifiers.
+var hashFunctions = map[string]crypto.Hash{
+	"sha256": crypto.SHA256,
+	"sha384": crypto.SHA384,
+	"sha512": crypto.SHA512,
+}
+
+// HashFunction returns the hash function of the given name.
+func HashFunction(name string) (crypto.Hash, error) {
+	if hash, ok := hashFunctions[name]; ok {
+		return hash, nil
+	}
+	return 0, ErrHashUnrecognized
+}
+
+// ParseHash will return the function and sum (in that order) of a hash
+// specifier of the form <function>-<sum>.
+func ParseHash(hash string) (string, string, error) {
+	parts := strings.SplitN(hash, "-", 2)
+	if len(parts) != 2 {
+		return "", "", ErrHashMalformed
+	}
+
+	return parts[0], parts[1], nil
+}
+
 // HashParts will return the sum and function (in that order) of the hash stored
 // in this Verification, or an error if there is an issue during parsing.
 func (v Verification) HashParts() (string, string, error) {
@@ -36,47 +83,56 @@ func (v Verification) HashParts() (string, string, error) {
 		// The hash can be nil
 		return "", "", nil
 	}
-	parts := strings.SplitN(*v.Hash, "-", 2)
-	if len(parts) != 2 {
-		return "", "", ErrHashMalformed
-	}
+	return ParseHash(*v.Hash)
+}
 
-	return parts[0], parts[1], nil
+// AllHashes returns the hash followed by the list of hashes. The resource must
+// match every one of them.
+func (v Verification) AllHashes() []string {
+	hashes := []string{}
+	if v.Hash != nil {
+		hashes = append(hashes, *v.Hash)
+	}
+	return append(hashes, v.Hashes...)
 }
 
 func (v Verification) Validate() report.Report {
 	r := report.Report{}
 
-	if v.Hash == nil {
-		// The hash can be nil
-		return r
+	if v.Hash != nil {
+		r.Merge(validateHash(*v.Hash, ""))
+	}
+	for i, hash := range v.Hashes {
+		r.Merge(validateHash(hash, fmt.Sprintf("/hashes/%d", i)))
 	}
 
-	function, sum, err := v.HashParts()
-	if err != nil {
+	return r
+}
+
+func validateHash(hash string, path string) report.Report {
+	r := report.Report{}
+	addErr := func(err error, code string) {
 		r.Add(report.Entry{
 			Message: err.Error(),
+			Code:    code,
 			Kind:    report.EntryError,
+			Path:    path,
 		})
+	}
+
+	function, sum, err := ParseHash(hash)
+	if err != nil {
+		addErr(err, CodeHashMalformed)
 		return r
 	}
-	var hash crypto.Hash
-	switch function {
-	case "sha512":
-		hash = crypto.SHA512
-	default:
-		r.Add(report.Entry{
-			Message: ErrHashUnrecognized.Error(),
-			Kind:    report.EntryError,
-		})
+	h, err := HashFunction(function)
+	if err != nil {
+		addErr(err, CodeHashUnrecognized)
 		return r
 	}
 
-	if len(sum) != hex.EncodedLen(hash.Size()) {
-		r.Add(report.Entry{
-			Message: ErrHashWrongSize.Error(),
-			Kind:    report.EntryError,
-		})
+	if len(sum) != hex.EncodedLen(h.Size()) {
+		addErr(ErrHashWrongSize, CodeHashWrongSize)
 	}
 
 	return r
@@ -17,8 +17,6 @@ package types
 import (
 	"reflect"
 	"testing"
-
-	"github.com/coreos/ignition/config/validate/report"
 )
 
 func TestHashParts(t *testing.T) {
@@ -58,12 +56,16 @@ func TestHashValidate(t *testing.T) {
 		v Verification
 	}
 	type out struct {
-		err error
+		err  error
+		code string
+		path string
 	}
 
 	h1 := "xor-abcdef"
 	h2 := "sha512-123"
 	h3 := "sha512-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	h4 := "sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	h5 := "sha384-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 
 	tests := []struct {
 		in  in
@@ -71,21 +73,43 @@ func TestHashValidate(t *testing.T) {
 	}{
 		{
 			in:  in{v: Verification{Hash: &h1}},
-			out: out{err: ErrHashUnrecognized},
+			out: out{err: ErrHashUnrecognized, code: CodeHashUnrecognized},
 		},
 		{
 			in:  in{v: Verification{Hash: &h2}},
-			out: out{err: ErrHashWrongSize},
+			out: out{err: ErrHashWrongSize, code: CodeHashWrongSize},
 		},
 		{
 			in:  in{v: Verification{Hash: &h3}},
 			out: out{},
 		},
+		{
+			in:  in{v: Verification{Hash: &h4}},
+			out: out{},
+		},
+		{
+			in:  in{v: Verification{Hash: &h5}},
+			out: out{},
+		},
+		{
+			in:  in{v: Verification{Hash: &h4, Hashes: []string{h3, h5}}},
+			out: out{},
+		},
+		{
+			in:  in{v: Verification{Hashes: []string{h3, h2}}},
+			out: out{err: ErrHashWrongSize, code: CodeHashWrongSize, path: "/hashes/1"},
+		},
+		{
+			in:  in{v: Verification{Hashes: []string{h1}}},
+			out: out{err: ErrHashUnrecognized, code: CodeHashUnrecognized, path: "/hashes/0"},
+		},
 	}
 
 	for i, test := range tests {
+		expected := reportFromError(test.out.err, test.out.code)
+		expected.AddPath(test.out.path)
 		err := test.in.v.Validate()
-		if !reflect.DeepEqual(report.ReportFromError(test.out.err, report.EntryError), err) {
+		if !reflect.DeepEqual(expected, err) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
 		}
 	}
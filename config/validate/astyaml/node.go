@@ -0,0 +1,122 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/coreos/ignition/config/validate"
+	"go4.org/errorutil"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// YamlNode is a node of a YAML document, along with the node of the mapping
+// key it is the value of (if any).
+type YamlNode struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// FromYamlRoot returns the YamlNode of the content of a YAML document node.
+func FromYamlRoot(n *yaml.Node) YamlNode {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	return YamlNode{value: n}
+}
+
+func (n YamlNode) ValueLineCol(source io.ReadSeeker) (int, int, string) {
+	if n.value == nil {
+		return 0, 0, ""
+	}
+	return posFromLineCol(n.value.Line, n.value.Column, source)
+}
+
+func (n YamlNode) KeyLineCol(source io.ReadSeeker) (int, int, string) {
+	if n.key == nil {
+		return n.ValueLineCol(source)
+	}
+	return posFromLineCol(n.key.Line, n.key.Column, source)
+}
+
+func (n YamlNode) LiteralValue() interface{} {
+	if n.value == nil {
+		return nil
+	}
+	var v interface{}
+	if err := n.value.Decode(&v); err != nil {
+		return nil
+	}
+	return v
+}
+
+func (n YamlNode) SliceChild(index int) (validate.AstNode, bool) {
+	value := resolveAlias(n.value)
+	if value != nil && value.Kind == yaml.SequenceNode && index < len(value.Content) {
+		return YamlNode{value: value.Content[index]}, true
+	}
+	return YamlNode{}, false
+}
+
+func (n YamlNode) KeyValueMap() (map[string]validate.AstNode, bool) {
+	value := resolveAlias(n.value)
+	if value == nil || value.Kind != yaml.MappingNode {
+		return nil, false
+	}
+	kvmap := map[string]validate.AstNode{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		kvmap[value.Content[i].Value] = YamlNode{key: value.Content[i], value: value.Content[i+1]}
+	}
+	return kvmap, true
+}
+
+// Tag returns "json" since YAML configs are converted to JSON before being
+// unmarshalled, so their keys are the names from the json struct tags.
+func (n YamlNode) Tag() string {
+	return "json"
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// posFromLineCol returns the line and column along with a highlight of that
+// position in the same format as the json nodes. The reader is reset to the
+// start afterwards.
+func posFromLineCol(line, col int, source io.ReadSeeker) (int, int, string) {
+	if source == nil || line == 0 {
+		return 0, 0, ""
+	}
+
+	// errorutil highlights byte offsets, so find the offset of line:col.
+	offset := int64(0)
+	r := bufio.NewReader(source)
+	for l := 1; l < line; l++ {
+		text, err := r.ReadString('\n')
+		offset += int64(len(text))
+		if err != nil {
+			break
+		}
+	}
+	source.Seek(0, 0)
+
+	_, _, highlight := errorutil.HighlightBytePosition(source, offset+int64(col))
+	source.Seek(0, 0) // Reset the reader to the start so the next call isn't relative to this position
+	return line, col, highlight
+}
@@ -85,6 +85,16 @@ func (r *Report) AddPosition(line, col int, highlight string) {
 	}
 }
 
+// AddPath sets the Path field of all the entries with an empty Path to path. Like AddPosition, this lets entries be created
+// without knowledge of where in the config they occurred, with the location filled in as the validation unwinds.
+func (r *Report) AddPath(path string) {
+	for i, e := range r.Entries {
+		if e.Path == "" {
+			r.Entries[i].Path = path
+		}
+	}
+}
+
 func (r *Report) Add(e Entry) {
 	r.Entries = append(r.Entries, e)
 }
@@ -122,18 +132,31 @@ func (r Report) IsDeprecated() bool {
 }
 
 type Entry struct {
-	Kind      entryKind `json:"kind"`
-	Message   string    `json:"message"`
-	Line      int       `json:"line,omitempty"`
-	Column    int       `json:"column,omitempty"`
-	Highlight string    `json:"-"`
+	Kind    entryKind `json:"kind"`
+	Message string    `json:"message"`
+	// Code is a stable, machine-readable identifier of the problem.
+	Code string `json:"code"`
+	// Path is a JSON pointer (RFC 6901) to the offending node of the config.
+	Path      string `json:"path"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	Highlight string `json:"-"`
+	// RelatedPath, RelatedLine, and RelatedColumn locate a second node involved in the problem, such as the other side
+	// of a conflict.
+	RelatedPath   string `json:"relatedPath,omitempty"`
+	RelatedLine   int    `json:"relatedLine,omitempty"`
+	RelatedColumn int    `json:"relatedColumn,omitempty"`
 }
 
 func (e Entry) String() string {
+	related := ""
+	if e.RelatedLine != 0 {
+		related = fmt.Sprintf(" (see also line %d, column %d)", e.RelatedLine, e.RelatedColumn)
+	}
 	if e.Line != 0 {
-		return fmt.Sprintf("%s at line %d, column %d\n%s%v", e.Kind.String(), e.Line, e.Column, e.Highlight, e.Message)
+		return fmt.Sprintf("%s at line %d, column %d\n%s%v%s", e.Kind.String(), e.Line, e.Column, e.Highlight, e.Message, related)
 	}
-	return fmt.Sprintf("%s: %v", e.Kind.String(), e.Message)
+	return fmt.Sprintf("%s: %v%s", e.Kind.String(), e.Message, related)
 }
 
 type entryKind int
@@ -18,11 +18,17 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/coreos/ignition/config/validate/report"
 )
 
+const (
+	CodeUnrecognizedKey = "unrecognized-key"
+	CodeKeySuggestion   = "key-suggestion"
+)
+
 type validator interface {
 	Validate() report.Report
 }
@@ -55,7 +61,17 @@ type AstNode interface {
 
 // Validate walks down a struct tree calling Validate on every node that implements it, building
 // A report of all the errors, warnings, info, and deprecations it encounters
-func Validate(vObj reflect.Value, ast AstNode, source io.ReadSeeker) (r report.Report) {
+func Validate(vObj reflect.Value, ast AstNode, source io.ReadSeeker) report.Report {
+	return validate(vObj, ast, source, "")
+}
+
+// validate is the implementation of Validate. path is the JSON pointer of vObj in the config and
+// is recorded in every entry that is reported for vObj.
+func validate(vObj reflect.Value, ast AstNode, source io.ReadSeeker, path string) (r report.Report) {
+	defer func() {
+		r.AddPath(path)
+	}()
+
 	if !vObj.IsValid() {
 		return
 	}
@@ -74,6 +90,7 @@ func Validate(vObj reflect.Value, ast AstNode, source io.ReadSeeker) (r report.R
 		((vObj.Kind() != reflect.Ptr) ||
 			(!vObj.IsNil() && !vObj.Elem().Type().Implements(reflect.TypeOf((*validator)(nil)).Elem()))) {
 		sub_r := obj.Validate()
+		locateEntries(&sub_r, ast, source, path)
 		sub_r.AddPosition(line, col, highlight)
 		r.Merge(sub_r)
 
@@ -85,11 +102,11 @@ func Validate(vObj reflect.Value, ast AstNode, source io.ReadSeeker) (r report.R
 
 	switch vObj.Kind() {
 	case reflect.Ptr:
-		sub_report := Validate(vObj.Elem(), ast, source)
+		sub_report := validate(vObj.Elem(), ast, source, path)
 		sub_report.AddPosition(line, col, "")
 		r.Merge(sub_report)
 	case reflect.Struct:
-		sub_report := validateStruct(vObj, ast, source)
+		sub_report := validateStruct(vObj, ast, source, path)
 		sub_report.AddPosition(line, col, "")
 		r.Merge(sub_report)
 	case reflect.Slice:
@@ -100,7 +117,7 @@ func Validate(vObj reflect.Value, ast AstNode, source io.ReadSeeker) (r report.R
 					sub_node = n
 				}
 			}
-			sub_report := Validate(vObj.Index(i), sub_node, source)
+			sub_report := validate(vObj.Index(i), sub_node, source, fmt.Sprintf("%s/%d", path, i))
 			sub_report.AddPosition(line, col, "")
 			r.Merge(sub_report)
 		}
@@ -108,6 +125,53 @@ func Validate(vObj reflect.Value, ast AstNode, source io.ReadSeeker) (r report.R
 	return
 }
 
+// locateEntries resolves the entries of r which have a Path (or RelatedPath), relative to the node being validated, to the
+// position of the node they refer to and then makes their Path absolute by prefixing it with path. This allows
+// Validate functions of parent nodes (e.g. rules which cross-reference multiple parts of the config) to point at a
+// specific child node.
+func locateEntries(r *report.Report, ast AstNode, source io.ReadSeeker, path string) {
+	for i, e := range r.Entries {
+		if e.RelatedPath != "" {
+			if ast != nil && e.RelatedLine == 0 {
+				r.Entries[i].RelatedLine, r.Entries[i].RelatedColumn, _ = findNode(ast, e.RelatedPath).ValueLineCol(source)
+			}
+			r.Entries[i].RelatedPath = path + e.RelatedPath
+		}
+		if e.Path == "" {
+			continue
+		}
+		if ast != nil && e.Line == 0 {
+			r.Entries[i].Line, r.Entries[i].Column, r.Entries[i].Highlight = findNode(ast, e.Path).ValueLineCol(source)
+		}
+		r.Entries[i].Path = path + e.Path
+	}
+}
+
+// findNode returns the node referred to by the JSON pointer path, relative to ast. If the node does not exist
+// (e.g. the field was not specified), its deepest existing ancestor is returned.
+func findNode(ast AstNode, path string) AstNode {
+	node := ast
+	for _, token := range strings.Split(path, "/")[1:] {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		if keys, ok := node.KeyValueMap(); ok {
+			child, ok := keys[token]
+			if !ok {
+				break
+			}
+			node = child
+		} else if index, err := strconv.Atoi(token); err == nil {
+			child, ok := node.SliceChild(index)
+			if !ok {
+				break
+			}
+			node = child
+		} else {
+			break
+		}
+	}
+	return node
+}
+
 func ValidateWithoutSource(cfg reflect.Value) (report report.Report) {
 	return Validate(cfg, nil, nil)
 }
@@ -137,13 +201,16 @@ func getFields(vObj reflect.Value) []field {
 	return ret
 }
 
-func validateStruct(vObj reflect.Value, ast AstNode, source io.ReadSeeker) report.Report {
+func validateStruct(vObj reflect.Value, ast AstNode, source io.ReadSeeker, path string) report.Report {
 	r := report.Report{}
 
 	// isFromObject will be true if this struct was unmarshalled from a JSON object.
 	keys, isFromObject := map[string]AstNode{}, false
+	// tagName is the struct tag used to name the fields in the path of the entries.
+	tagName := "json"
 	if ast != nil {
 		keys, isFromObject = ast.KeyValueMap()
+		tagName = ast.Tag()
 	}
 
 	// Maintain a set of key's that have been used.
@@ -159,9 +226,11 @@ func validateStruct(vObj reflect.Value, ast AstNode, source io.ReadSeeker) repor
 		// This ensures the line numbers reported from all sub-structs are 0 and will be changed by AddPosition
 		var src io.ReadSeeker
 
+		tag := strings.SplitN(f.Type.Tag.Get(tagName), ",", 2)[0]
+		fieldPath := path + "/" + escapePointer(tag)
+
 		// Try to determine the json.Node that corrosponds with the struct field
 		if isFromObject {
-			tag := strings.SplitN(f.Type.Tag.Get(ast.Tag()), ",", 2)[0]
 			// Save the tag so we have a list of all the tags in the struct
 			tags = append(tags, tag)
 			// mark that this key was used
@@ -187,10 +256,11 @@ func validateStruct(vObj reflect.Value, ast AstNode, source io.ReadSeeker) repor
 			res := funct.Call(nil)
 			sub_report := res[0].Interface().(report.Report)
 			sub_report.AddPosition(line, col, "")
+			sub_report.AddPath(fieldPath)
 			r.Merge(sub_report)
 		}
 
-		sub_report := Validate(f.Value, sub_node, src)
+		sub_report := validate(f.Value, sub_node, src, fieldPath)
 		sub_report.AddPosition(line, col, "")
 		r.Merge(sub_report)
 	}
@@ -209,6 +279,8 @@ func validateStruct(vObj reflect.Value, ast AstNode, source io.ReadSeeker) repor
 		r.Add(report.Entry{
 			Kind:      report.EntryWarning,
 			Message:   fmt.Sprintf("Config has unrecognized key: %s", k),
+			Code:      CodeUnrecognizedKey,
+			Path:      path + "/" + escapePointer(k),
 			Line:      line,
 			Column:    col,
 			Highlight: highlight,
@@ -218,6 +290,8 @@ func validateStruct(vObj reflect.Value, ast AstNode, source io.ReadSeeker) repor
 			r.Add(report.Entry{
 				Kind:      report.EntryInfo,
 				Message:   fmt.Sprintf("Did you mean %s instead of %s", typo, k),
+				Code:      CodeKeySuggestion,
+				Path:      path + "/" + escapePointer(k),
 				Line:      line,
 				Column:    col,
 				Highlight: highlight,
@@ -238,3 +312,8 @@ func similar(str string, candidates []string) string {
 	}
 	return ""
 }
+
+// escapePointer escapes a key for use as a reference token of a JSON pointer.
+func escapePointer(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
@@ -31,7 +31,9 @@ func TestValidate(t *testing.T) {
 		cfg Config
 	}
 	type out struct {
-		err error
+		err  error
+		code string
+		path string
 	}
 
 	tests := []struct {
@@ -44,23 +46,23 @@ func TestValidate(t *testing.T) {
 		},
 		{
 			in:  in{cfg: Config{}},
-			out: out{err: ErrInvalidVersion},
+			out: out{err: ErrInvalidVersion, code: CodeInvalidVersion, path: "/ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "invalid.version"}}},
-			out: out{err: ErrInvalidVersion},
+			out: out{err: ErrInvalidVersion, code: CodeInvalidVersion, path: "/ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "2.2.0"}}},
-			out: out{err: ErrNewVersion},
+			out: out{err: ErrNewVersion, code: CodeNewVersion, path: "/ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "3.0.0"}}},
-			out: out{err: ErrNewVersion},
+			out: out{err: ErrNewVersion, code: CodeNewVersion, path: "/ignition"},
 		},
 		{
 			in:  in{cfg: Config{Ignition: Ignition{Version: "1.0.0"}}},
-			out: out{err: ErrOldVersion},
+			out: out{err: ErrOldVersion, code: CodeOldVersion, path: "/ignition"},
 		},
 		{
 			in: in{cfg: Config{
@@ -75,12 +77,18 @@ func TestValidate(t *testing.T) {
 					},
 				},
 			}},
-			out: out{errors.New("unrecognized hash function")},
+			out: out{err: errors.New("unrecognized hash function"), code: CodeHashUnrecognized, path: "/ignition/config/replace/verification"},
 		},
 		{
 			in: in{cfg: Config{
 				Ignition: Ignition{Version: semver.Version{Major: 2}.String()},
 				Storage: Storage{
+					Disks: []Disk{
+						{
+							Device:     "/dev/sda",
+							Partitions: []Partition{{Label: "ROOT", Number: 1}},
+						},
+					},
 					Filesystems: []Filesystem{
 						{
 							Name: "filesystem1",
@@ -113,13 +121,17 @@ func TestValidate(t *testing.T) {
 				Ignition: Ignition{Version: semver.Version{Major: 2}.String()},
 				Systemd:  Systemd{Units: []Unit{{Name: "foo.bar", Contents: "[Foo]\nfoo=qux"}}},
 			}},
-			out: out{err: errors.New("invalid systemd unit extension")},
+			out: out{err: errors.New("invalid systemd unit extension"), code: CodeInvalidSystemdExt, path: "/systemd/units/0/name"},
 		},
 	}
 
 	for i, test := range tests {
 		r := ValidateWithoutSource(reflect.ValueOf(test.in.cfg))
 		expectedReport := report.ReportFromError(test.out.err, report.EntryError)
+		for j := range expectedReport.Entries {
+			expectedReport.Entries[j].Code = test.out.code
+			expectedReport.Entries[j].Path = test.out.path
+		}
 		if !reflect.DeepEqual(expectedReport, r) {
 			t.Errorf("#%d: bad error: want %v, got %v", i, expectedReport, r)
 		}
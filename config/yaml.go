@@ -0,0 +1,122 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/coreos/ignition/config/types"
+	v2_0types "github.com/coreos/ignition/config/v2_0/types"
+	v2_1types "github.com/coreos/ignition/config/v2_1/types"
+	"github.com/coreos/ignition/config/validate"
+	astyaml "github.com/coreos/ignition/config/validate/astyaml"
+	"github.com/coreos/ignition/config/validate/report"
+
+	json "github.com/ajeddeloh/go-json"
+	"github.com/coreos/go-semver/semver"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ParseYAML parses a config written in YAML rather than JSON. The document
+// has the same structure as the JSON config. Configs of version 2.0.0 and
+// later are validated against the YAML document, using the types of their own
+// version, so the report points at positions in the YAML source. Version 1
+// configs are converted to JSON and parsed by Parse; their reports carry no
+// positions.
+func ParseYAML(rawConfig []byte) (types.Config, report.Report, error) {
+	if isEmpty(rawConfig) {
+		return types.Config{}, report.Report{}, ErrEmpty
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(rawConfig, &doc); err != nil {
+		return types.Config{}, report.ReportFromError(err, report.EntryError), ErrInvalid
+	}
+
+	var generic interface{}
+	if err := doc.Decode(&generic); err != nil {
+		return types.Config{}, report.ReportFromError(err, report.EntryError), ErrInvalid
+	}
+	jsonConfig, err := json.Marshal(generic)
+	if err != nil {
+		return types.Config{}, report.ReportFromError(err, report.EntryError), ErrInvalid
+	}
+
+	v, err := version(jsonConfig)
+	if err != nil {
+		return types.Config{}, report.ReportFromError(err, report.EntryError), err
+	}
+	switch v {
+	case semver.Version{Major: 1}:
+		config, r, err := Parse(jsonConfig)
+		// Positions in the report refer to the generated JSON, not the YAML.
+		for i := range r.Entries {
+			r.Entries[i].Line = 0
+			r.Entries[i].Column = 0
+			r.Entries[i].Highlight = ""
+			r.Entries[i].RelatedLine = 0
+			r.Entries[i].RelatedColumn = 0
+		}
+		return config, r, err
+	case semver.Version{Major: 2, Minor: 0}:
+		var config v2_0types.Config
+		if err := json.Unmarshal(jsonConfig, &config); err != nil {
+			return types.Config{}, report.ReportFromError(err, report.EntryError), ErrInvalid
+		}
+		if versionReport := config.Ignition.Version.Validate(); versionReport.IsFatal() {
+			return types.Config{}, versionReport, ErrInvalid
+		}
+		r := validateYAML(config, &doc, rawConfig)
+		if r.IsFatal() {
+			return types.Config{}, r, ErrInvalid
+		}
+		return TranslateFromV2_0(config), r, nil
+	case semver.Version{Major: 2, Minor: 1}:
+		var config v2_1types.Config
+		if err := json.Unmarshal(jsonConfig, &config); err != nil {
+			return types.Config{}, report.ReportFromError(err, report.EntryError), ErrInvalid
+		}
+		if versionReport := config.Ignition.Validate(); versionReport.IsFatal() {
+			return types.Config{}, versionReport, ErrInvalid
+		}
+		r := validateYAML(config, &doc, rawConfig)
+		if r.IsFatal() {
+			return types.Config{}, r, ErrInvalid
+		}
+		return TranslateFromV2_1(config), r, nil
+	}
+
+	var config types.Config
+	if err := json.Unmarshal(jsonConfig, &config); err != nil {
+		return types.Config{}, report.ReportFromError(err, report.EntryError), ErrInvalid
+	}
+	if versionReport := config.Ignition.Validate(); versionReport.IsFatal() {
+		return types.Config{}, versionReport, ErrInvalid
+	}
+
+	r := validateYAML(config, &doc, rawConfig)
+	if r.IsFatal() {
+		return types.Config{}, r, ErrInvalid
+	}
+
+	return config, r, nil
+}
+
+// validateYAML validates config, which is one of the versioned config types,
+// against the YAML document doc parsed from rawConfig.
+func validateYAML(config interface{}, doc *yaml.Node, rawConfig []byte) report.Report {
+	return validate.Validate(reflect.ValueOf(config), astyaml.FromYamlRoot(doc), bytes.NewReader(rawConfig))
+}
@@ -0,0 +1,168 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/config/validate"
+	"github.com/coreos/ignition/config/validate/report"
+)
+
+func TestParseYAML(t *testing.T) {
+	type in struct {
+		config []byte
+	}
+	type out struct {
+		config  types.Config
+		entries []report.Entry
+		err     error
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{config: []byte(``)},
+			out: out{err: ErrEmpty},
+		},
+		{
+			in: in{config: []byte(`ignition:
+  version: 2.2.0-experimental
+systemd:
+  units:
+    - name: foo.service
+      contents: "[Service]"
+`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+				Systemd: types.Systemd{
+					Units: []types.Unit{{Name: "foo.service", Contents: "[Service]"}},
+				},
+			}},
+		},
+		{
+			in: in{config: []byte(`ignition:
+  version: 2.2.0-experimental
+storage:
+  files:
+    - filesystem: root
+      path: /foo
+      Mode: 420
+`)},
+			out: out{
+				config: types.Config{
+					Ignition: types.Ignition{Version: types.MaxVersion.String()},
+					Storage: types.Storage{
+						Files: []types.File{{Node: types.Node{Filesystem: "root", Path: "/foo"}, FileEmbedded1: types.FileEmbedded1{Mode: 420}}},
+					},
+				},
+				entries: []report.Entry{
+					{Kind: report.EntryWarning, Code: validate.CodeUnrecognizedKey, Path: "/storage/files/0/Mode", Line: 7, Column: 7},
+					{Kind: report.EntryInfo, Code: validate.CodeKeySuggestion, Path: "/storage/files/0/Mode", Line: 7, Column: 7},
+				},
+			},
+		},
+		{
+			in: in{config: []byte(`ignition:
+  version: 2.2.0-experimental
+storage:
+  disks:
+    - device: relative
+`)},
+			out: out{
+				entries: []report.Entry{
+					{Kind: report.EntryError, Code: types.CodePathRelative, Path: "/storage/disks/0/device", Line: 5, Column: 7},
+				},
+				err: ErrInvalid,
+			},
+		},
+		{
+			in: in{config: []byte(`ignition:
+  version: 2.1.0
+`)},
+			out: out{config: types.Config{
+				Ignition: types.Ignition{Version: types.MaxVersion.String()},
+			}},
+		},
+		{
+			in: in{config: []byte(`ignition:
+  version: 2.1.0
+storage:
+  disks:
+    - device: relative
+`)},
+			out: out{
+				entries: []report.Entry{
+					{Kind: report.EntryError, Path: "/storage/disks/0/device", Line: 5, Column: 7},
+				},
+				err: ErrInvalid,
+			},
+		},
+		{
+			in: in{config: []byte(`ignition:
+  version: 2.0.0
+storage:
+  files:
+    - filesystem: root
+      path: /foo
+      Mode: 420
+`)},
+			out: out{
+				config: types.Config{
+					Ignition: types.Ignition{Version: types.MaxVersion.String()},
+					Storage: types.Storage{
+						Files: []types.File{{
+							Node: types.Node{
+								Filesystem: "root",
+								Path:       "/foo",
+								User:       types.NodeUser{ID: intToPtr(0)},
+								Group:      types.NodeGroup{ID: intToPtr(0)},
+							},
+							FileEmbedded1: types.FileEmbedded1{Mode: 420},
+						}},
+					},
+				},
+				entries: []report.Entry{
+					{Kind: report.EntryWarning, Code: validate.CodeUnrecognizedKey, Path: "/storage/files/0/Mode", Line: 7, Column: 7},
+					{Kind: report.EntryInfo, Code: validate.CodeKeySuggestion, Path: "/storage/files/0/Mode", Line: 7, Column: 7},
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		config, r, err := ParseYAML(test.in.config)
+		if test.out.err != err {
+			t.Errorf("#%d: bad error: want %v, got %v, report: %+v", i, test.out.err, err, r)
+		}
+		if !reflect.DeepEqual(test.out.config, config) {
+			t.Errorf("#%d: bad config: want %+v, got %+v", i, test.out.config, config)
+		}
+		if len(test.out.entries) != len(r.Entries) {
+			t.Errorf("#%d: bad report: want %d entries, got %+v", i, len(test.out.entries), r)
+			continue
+		}
+		for j, e := range test.out.entries {
+			got := r.Entries[j]
+			if got.Kind != e.Kind || got.Code != e.Code || got.Path != e.Path || got.Line != e.Line || got.Column != e.Column {
+				t.Errorf("#%d: bad entry %d: want %+v, got %+v", i, j, e, got)
+			}
+		}
+	}
+}
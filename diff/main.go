@@ -0,0 +1,125 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ignition-diff prints the semantic differences between two Ignition
+// configs. Like diff(1), it exits with exitSame if the configs are
+// equivalent, with exitDifferent if they differ, and with exitTrouble if
+// either config couldn't be read or parsed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/version"
+)
+
+const (
+	exitSame      = 0
+	exitDifferent = 1
+	exitTrouble   = 2
+)
+
+func main() {
+	flags := struct {
+		json    bool
+		version bool
+	}{}
+
+	flag.BoolVar(&flags.json, "json", false, "print the changes as JSON")
+	flag.BoolVar(&flags.version, "version", false, "print the version and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] old-config new-config\n\n", os.Args[0])
+		fmt.Fprint(os.Stderr, "Prints the differences between two configs, matching list entries by identity\n")
+		fmt.Fprint(os.Stderr, "(e.g. file path, unit name, user name or disk device) rather than position.\n")
+		fmt.Fprint(os.Stderr, "Either config may be \"-\" for stdin. Files ending in .yaml or .yml are parsed as YAML.\n\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExit status is %d if the configs are equivalent, %d if they differ, and %d on trouble.\n", exitSame, exitDifferent, exitTrouble)
+	}
+
+	flag.Parse()
+
+	if flags.version {
+		fmt.Printf("%s\n", version.String)
+		return
+	}
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(exitTrouble)
+	}
+
+	oldConfig, err := parse(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(exitTrouble)
+	}
+	newConfig, err := parse(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(1), err)
+		os.Exit(exitTrouble)
+	}
+
+	changes := config.Diff(oldConfig, newConfig)
+
+	if flags.json {
+		if changes == nil {
+			changes = []config.Change{}
+		}
+		if err := json.NewEncoder(os.Stdout).Encode(changes); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode changes: %v\n", err)
+			os.Exit(exitTrouble)
+		}
+	} else {
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+	}
+
+	if len(changes) > 0 {
+		os.Exit(exitDifferent)
+	}
+}
+
+// parse reads and parses the config in file ("-" for stdin). Parse reports
+// are printed to stderr.
+func parse(file string) (types.Config, error) {
+	var rawConfig []byte
+	var err error
+	if file == "-" {
+		rawConfig, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		rawConfig, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return types.Config{}, err
+	}
+
+	parse := config.Parse
+	if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+		parse = config.ParseYAML
+	}
+
+	cfg, r, err := parse(rawConfig)
+	for _, entry := range r.Entries {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, entry)
+	}
+	return cfg, err
+}
@@ -16,8 +16,12 @@ package exec
 
 import (
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/coreos/ignition/config"
@@ -35,84 +39,192 @@ import (
 
 const (
 	DefaultOnlineTimeout = time.Minute
+
+	// trustedKeysPath is a bundle of PEM encoded public keys, shipped in the
+	// initramfs, which are trusted to sign configs and file contents.
+	trustedKeysPath = "/usr/lib/ignition/trusted-keys.pem"
 )
 
 var (
-	baseConfig = types.Config{
-		Ignition: types.Ignition{Version: types.MaxVersion.String()},
-		Storage: types.Storage{
-			Filesystems: []types.Filesystem{{
-				Name: "root",
-				Path: func(p string) *string { return &p }("/sysroot"),
-			}},
-		},
-	}
+	ErrNoMetadata = errors.New("config uses templates but the platform does not provide metadata")
 )
 
 // Engine represents the entity that fetches and executes a configuration.
+// Root is the directory the system is provisioned into: the "root" filesystem
+// is mapped to it and users and groups are managed and looked up within it.
 type Engine struct {
 	ConfigCache       string
 	Logger            *log.Logger
 	Root              string
 	FetchFunc         providers.FuncFetchConfig
+	FetchMetadataFunc providers.FuncFetchMetadata
 	OemBaseConfig     types.Config
 	DefaultUserConfig types.Config
 
 	client resource.HttpClient
+	keys   util.Keyring
 }
 
 // Run executes the stage of the given name. It returns true if the stage
-// successfully ran and false if there were any errors.
+// successfully ran and false if there were any errors. The fetch stage
+// acquires and renders the config and writes it to the config cache. All
+// other stages only apply the cached config, and fail if it hasn't been
+// fetched yet.
 func (e Engine) Run(stageName string) bool {
-	e.client = resource.NewHttpClient(e.Logger)
+	if stageName == stages.FetchName {
+		return e.fetch()
+	}
 
-	cfg, err := e.acquireConfig()
-	switch err {
-	case nil:
-	case config.ErrCloudConfig, config.ErrScript, config.ErrEmpty:
-		e.Logger.Info("%v: ignoring user-provided config", err)
-		cfg = e.DefaultUserConfig
-	default:
-		e.Logger.Crit("failed to acquire config: %v", err)
+	cfg, ok := e.cachedConfig()
+	if !ok {
+		return false
+	}
+
+	return e.runStage(stageName, cfg, nil)
+}
+
+// Plan prints the operations which the stage of the given name would perform,
+// one per line, without performing them. It returns true if the stage could be
+// planned and false if there were any errors.
+func (e Engine) Plan(stageName string) bool {
+	cfg, ok := e.cachedConfig()
+	if !ok {
 		return false
 	}
 
-	e.Logger.PushPrefix(stageName)
+	e.Logger.PushPrefix("%s", stageName)
 	defer e.Logger.PopPrefix()
-	return stages.Get(stageName).Create(e.Logger, &e.client, e.Root).Run(config.Append(baseConfig, config.Append(e.OemBaseConfig, cfg)))
+	ops, ok := stages.Get(stageName).Create(e.Logger, &e.client, e.Root, nil).Plan(cfg)
+	for _, op := range ops {
+		fmt.Println(op)
+	}
+	return ok
 }
 
-// acquireConfig returns the configuration, first checking a local cache
-// before attempting to fetch it from the provider.
-func (e *Engine) acquireConfig() (cfg types.Config, err error) {
-	// First try read the config @ e.ConfigCache.
-	b, err := ioutil.ReadFile(e.ConfigCache)
-	if err == nil {
-		if err = json.Unmarshal(b, &cfg); err != nil {
-			e.Logger.Crit("failed to parse cached config: %v", err)
-		}
-		return
+// fetch runs the fetch stage: it acquires the config, renders it into the one
+// which the other stages apply and writes that to the config cache. Nothing is
+// fetched if the cache already exists, so that every stage applies the same
+// config. It returns false if there were any errors, which have been logged.
+func (e Engine) fetch() bool {
+	e.Logger.PushPrefix(stages.FetchName)
+	defer e.Logger.PopPrefix()
+
+	if _, err := os.Stat(e.ConfigCache); err == nil {
+		e.Logger.Info("using the config already cached at %q", e.ConfigCache)
+		return true
 	}
 
-	// (Re)Fetch the config if the cache is unreadable.
-	cfg, err = e.fetchProviderConfig()
-	if err != nil {
-		e.Logger.Crit("failed to fetch config: %s", err)
-		return
+	cfg, ok := e.prepareConfig()
+	if !ok {
+		return false
 	}
 
-	// Populate the config cache.
-	b, err = json.Marshal(cfg)
+	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		e.Logger.Crit("failed to marshal cached config: %v", err)
-		return
+		return false
 	}
-	if err = ioutil.WriteFile(e.ConfigCache, b, 0640); err != nil {
+	if err := ioutil.WriteFile(e.ConfigCache, b, 0640); err != nil {
 		e.Logger.Crit("failed to write cached config: %v", err)
-		return
+		return false
 	}
 
-	return
+	return true
+}
+
+// prepareConfig fetches the config from the provider and renders it into the
+// one which the stages apply. It returns false if there were any errors, which
+// have been logged.
+func (e *Engine) prepareConfig() (types.Config, bool) {
+	e.client = resource.NewHttpClient(e.Logger, types.Timeouts{})
+
+	trustedKeys, err := e.trustedKeys()
+	if err != nil {
+		e.Logger.Crit("failed to load trusted keys: %v", err)
+		return types.Config{}, false
+	}
+	if e.keys, err = util.NewKeyring(trustedKeys); err != nil {
+		e.Logger.Crit("failed to parse trusted keys: %v", err)
+		return types.Config{}, false
+	}
+
+	cfg, err := e.fetchProviderConfig()
+	switch err {
+	case nil:
+	case config.ErrCloudConfig, config.ErrScript, config.ErrEmpty:
+		e.Logger.Info("%v: ignoring user-provided config", err)
+		cfg = e.DefaultUserConfig
+	default:
+		e.Logger.Crit("failed to fetch config: %v", err)
+		return types.Config{}, false
+	}
+
+	// Rebuild the client so that templates are rendered with the timeouts of
+	// the final config.
+	e.client = resource.NewHttpClient(e.Logger, cfg.Ignition.Timeouts)
+
+	if len(cfg.Ignition.Security.TrustedKeys) > 0 {
+		e.Logger.Warning("ignoring trusted keys of the user config; keys are only trusted from the OEM base config and %s", trustedKeysPath)
+	}
+
+	cfg = config.Render(cfg, e.OemBaseConfig, e.Root)
+
+	// The referenced configs were already evaluated by fetchProviderConfig.
+	cfg.Ignition.Config = types.IgnitionConfig{}
+
+	// Hand the stages only the keys which are actually trusted.
+	cfg.Ignition.Security.TrustedKeys = trustedKeys
+
+	// Each config was validated on its own, but conflicting storage paths may
+	// only appear once they have been merged. Catch those before any stage
+	// starts modifying the system.
+	r := cfg.Storage.Validate()
+	e.logReport(r)
+	if r.IsFatal() {
+		e.Logger.Crit("merged config has conflicting storage paths")
+		return types.Config{}, false
+	}
+
+	if usesTemplates(cfg) {
+		if cfg, err = e.renderTemplates(cfg); err != nil {
+			e.Logger.Crit("failed to render templates: %v", err)
+			return types.Config{}, false
+		}
+	}
+
+	return cfg, true
+}
+
+// cachedConfig returns the config written to the config cache by the fetch
+// stage. The client is set up with the config's timeouts for the stages to
+// fetch remote resources with. It returns false if there were any errors,
+// which have been logged.
+func (e *Engine) cachedConfig() (types.Config, bool) {
+	b, err := ioutil.ReadFile(e.ConfigCache)
+	if os.IsNotExist(err) {
+		e.Logger.Crit("no config is cached at %q; the %s stage needs to run first", e.ConfigCache, stages.FetchName)
+		return types.Config{}, false
+	} else if err != nil {
+		e.Logger.Crit("failed to read cached config: %v", err)
+		return types.Config{}, false
+	}
+
+	var cfg types.Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		e.Logger.Crit("failed to parse cached config: %v", err)
+		return types.Config{}, false
+	}
+
+	e.client = resource.NewHttpClient(e.Logger, cfg.Ignition.Timeouts)
+	return cfg, true
+}
+
+// runStage executes the stage of the given name against cfg, waiting for
+// devices with waitOnDevices (see stages.StageCreator).
+func (e Engine) runStage(stageName string, cfg types.Config, waitOnDevices util.WaitOnDevicesFunc) bool {
+	e.Logger.PushPrefix("%s", stageName)
+	defer e.Logger.PopPrefix()
+	return stages.Get(stageName).Create(e.Logger, &e.client, e.Root, waitOnDevices).Run(cfg)
 }
 
 // fetchProviderConfig returns the externally-provided configuration. It first
@@ -121,7 +233,7 @@ func (e *Engine) acquireConfig() (cfg types.Config, err error) {
 // check's the engine's provider. An error is returned if the provider is
 // unavailable. This will also render the config (see renderConfig) before
 // returning.
-func (e Engine) fetchProviderConfig() (types.Config, error) {
+func (e *Engine) fetchProviderConfig() (types.Config, error) {
 	cfg, r, err := cmdline.FetchConfig(e.Logger, &e.client)
 	if err == providers.ErrNoProvider {
 		cfg, r, err = e.FetchFunc(e.Logger, &e.client)
@@ -132,6 +244,9 @@ func (e Engine) fetchProviderConfig() (types.Config, error) {
 		return types.Config{}, err
 	}
 
+	// Use the timeouts of the provided config for fetching referenced configs.
+	e.client = resource.NewHttpClient(e.Logger, cfg.Ignition.Timeouts)
+
 	return e.renderConfig(cfg)
 }
 
@@ -140,10 +255,18 @@ func (e Engine) fetchProviderConfig() (types.Config, error) {
 // set, the referenced and evaluted config will be returned. Otherwise, if
 // "ignition.config.append" is set, each of the referenced configs will be
 // evaluated and appended to the provided config. If neither option is set, the
-// provided config will be returned unmodified.
+// provided config will be returned unmodified. The timeouts of each fetched
+// config take effect before any of the configs it references are fetched.
 func (e *Engine) renderConfig(cfg types.Config) (types.Config, error) {
 	if cfgRef := cfg.Ignition.Config.Replace; cfgRef != nil {
-		return e.fetchReferencedConfig(*cfgRef)
+		newCfg, err := e.fetchReferencedConfig(*cfgRef)
+		if err != nil {
+			return types.Config{}, err
+		}
+
+		e.client = resource.NewHttpClient(e.Logger, newCfg.Ignition.Timeouts)
+
+		return e.renderConfig(newCfg)
 	}
 
 	appendedCfg := cfg
@@ -153,13 +276,21 @@ func (e *Engine) renderConfig(cfg types.Config) (types.Config, error) {
 			return newCfg, err
 		}
 
-		appendedCfg = config.Append(appendedCfg, newCfg)
+		// Merging before rendering lets the new config's timeouts apply
+		// to the configs it references.
+		e.client = resource.NewHttpClient(e.Logger, config.Merge(appendedCfg, newCfg).Ignition.Timeouts)
+
+		newCfg, err = e.renderConfig(newCfg)
+		if err != nil {
+			return newCfg, err
+		}
+
+		appendedCfg = config.Merge(appendedCfg, newCfg)
 	}
 	return appendedCfg, nil
 }
 
-// fetchReferencedConfig fetches, renders, and attempts to verify the requested
-// config.
+// fetchReferencedConfig fetches and attempts to verify the requested config.
 func (e Engine) fetchReferencedConfig(cfgRef types.ConfigReference) (types.Config, error) {
 	u, err := url.Parse(cfgRef.Source)
 	if err != nil {
@@ -170,7 +301,12 @@ func (e Engine) fetchReferencedConfig(cfgRef types.ConfigReference) (types.Confi
 		return types.Config{}, err
 	}
 
-	if err := util.AssertValid(cfgRef.Verification, rawCfg); err != nil {
+	sig, err := util.FetchSignature(e.Logger, &e.client, cfgRef.Verification, cfgRef.Source)
+	if err != nil {
+		return types.Config{}, err
+	}
+
+	if err := util.AssertValid(cfgRef.Verification, rawCfg, sig, e.keys); err != nil {
 		return types.Config{}, err
 	}
 
@@ -180,7 +316,29 @@ func (e Engine) fetchReferencedConfig(cfgRef types.ConfigReference) (types.Confi
 		return types.Config{}, err
 	}
 
-	return e.renderConfig(cfg)
+	return cfg, nil
+}
+
+// trustedKeys returns the keys trusted to sign configs and file contents: those
+// of the OEM base config and those shipped in the initramfs. Keys specified by
+// the user config itself are never trusted.
+func (e Engine) trustedKeys() ([]string, error) {
+	keys := append([]string{}, e.OemBaseConfig.Ignition.Security.TrustedKeys...)
+
+	bundle, err := ioutil.ReadFile(trustedKeysPath)
+	if os.IsNotExist(err) {
+		return keys, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		if block, bundle = pem.Decode(bundle); block == nil {
+			break
+		}
+		keys = append(keys, string(pem.EncodeToMemory(block)))
+	}
+	return keys, nil
 }
 
 func (e Engine) logReport(r report.Report) {
@@ -197,3 +355,82 @@ func (e Engine) logReport(r report.Report) {
 		}
 	}
 }
+
+// usesTemplates returns true if any file or unit in the config opted into
+// templating.
+func usesTemplates(cfg types.Config) bool {
+	for _, f := range cfg.Storage.Files {
+		if f.Contents.Template {
+			return true
+		}
+		for _, contents := range f.Append {
+			if contents.Template {
+				return true
+			}
+		}
+	}
+	for _, u := range cfg.Systemd.Units {
+		if u.Template {
+			return true
+		}
+	}
+	return false
+}
+
+// renderTemplates fetches the platform metadata and substitutes it into the
+// templated files and units of the config. Since the variables are resolved
+// before any stage runs, a missing variable fails the stage without having
+// modified the system.
+func (e *Engine) renderTemplates(cfg types.Config) (types.Config, error) {
+	if e.FetchMetadataFunc == nil {
+		return types.Config{}, ErrNoMetadata
+	}
+	metadata, err := e.FetchMetadataFunc(e.Logger, &e.client)
+	if err != nil {
+		return types.Config{}, err
+	}
+
+	files := make([]types.File, len(cfg.Storage.Files))
+	for i, f := range cfg.Storage.Files {
+		if f, err = util.RenderFileTemplate(e.Logger, &e.client, f, metadata, e.keys); err != nil {
+			return types.Config{}, err
+		}
+		files[i] = f
+	}
+	cfg.Storage.Files = files
+
+	units := make([]types.Unit, len(cfg.Systemd.Units))
+	for i, u := range cfg.Systemd.Units {
+		if u.Template {
+			if u, err = renderUnitTemplate(u, metadata); err != nil {
+				return types.Config{}, err
+			}
+		}
+		units[i] = u
+	}
+	cfg.Systemd.Units = units
+
+	return cfg, nil
+}
+
+// renderUnitTemplate substitutes the metadata into the contents of the unit
+// and each of its dropins.
+func renderUnitTemplate(u types.Unit, metadata map[string]string) (types.Unit, error) {
+	contents, err := util.RenderTemplate(u.Name, u.Contents, metadata)
+	if err != nil {
+		return types.Unit{}, err
+	}
+	u.Contents = contents
+
+	dropins := make([]types.Dropin, len(u.Dropins))
+	for i, d := range u.Dropins {
+		if d.Contents, err = util.RenderTemplate(u.Name+"/"+d.Name, d.Contents, metadata); err != nil {
+			return types.Unit{}, err
+		}
+		dropins[i] = d
+	}
+	u.Dropins = dropins
+	u.Template = false
+
+	return u, nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/exec/util"
+)
+
+// RunImage applies the config to the raw disk image at image rather than to
+// the running system. The config is fetched first, unless it is already
+// cached. The image is attached to a loop device, which stands in for device
+// (e.g. /dev/sda) and its partitions in the config. The disks stage is run
+// against it, after which the partition rootDevice (e.g. /dev/sda9) is mounted
+// at Root and the mount, files and umount stages are run. Devices are waited
+// for by polling udev and sysfs, so neither systemd nor D-Bus are needed. It
+// returns true if the stages successfully ran and the image was cleanly
+// unmounted and detached, and false if there were any errors.
+func (e Engine) RunImage(image, device, rootDevice string) (ok bool) {
+	if !e.fetch() {
+		return false
+	}
+	cfg, cached := e.cachedConfig()
+	if !cached {
+		return false
+	}
+
+	var loop string
+	if err := e.Logger.LogOp(
+		func() (err error) {
+			loop, err = util.AttachLoopDevice(image)
+			return
+		},
+		"attaching %q to a loop device", image,
+	); err != nil {
+		e.Logger.Crit("failed to attach image: %v", err)
+		return false
+	}
+	defer func() {
+		if err := e.Logger.LogOp(
+			func() error { return util.DetachLoopDevice(loop) },
+			"detaching %q", loop,
+		); err != nil {
+			ok = false
+		}
+	}()
+	e.Logger.Info("attached %q to %q", image, loop)
+
+	root := util.MapDevicePath(rootDevice, device, loop)
+	if root == rootDevice {
+		e.Logger.Crit("root device %q is not a partition of %q", rootDevice, device)
+		return false
+	}
+
+	cfg, err := mapImageDevices(cfg, device, loop)
+	if err != nil {
+		e.Logger.Crit("failed to map devices onto %q: %v", loop, err)
+		return false
+	}
+
+	if !e.runStage("disks", cfg, util.PollDevices) {
+		return false
+	}
+
+	if err := e.mountImageRoot(root); err != nil {
+		e.Logger.Crit("failed to mount root device: %v", err)
+		return false
+	}
+	defer func() {
+		// An image whose root isn't cleanly unmounted may be corrupt.
+		if err := e.Logger.LogOp(
+			func() error { return syscall.Unmount(e.Root, 0) },
+			"unmounting %q at %q", root, e.Root,
+		); err != nil {
+			ok = false
+		}
+	}()
+
+	if !e.runStage("mount", cfg, util.PollDevices) {
+		return false
+	}
+	defer func() {
+		if !e.runStage("umount", cfg, util.PollDevices) {
+			ok = false
+		}
+	}()
+
+	return e.runStage("files", cfg, util.PollDevices)
+}
+
+// mountImageRoot mounts the device dev at Root, which is created if needed.
+func (e Engine) mountImageRoot(dev string) error {
+	if err := util.PollDevices([]string{dev}, "root"); err != nil {
+		return err
+	}
+	format, err := util.FilesystemType(dev)
+	if err != nil {
+		return fmt.Errorf("failed to determine filesystem type of %q: %v", dev, err)
+	}
+	if err := os.MkdirAll(e.Root, 0755); err != nil {
+		return err
+	}
+	return e.Logger.LogOp(
+		func() error { return syscall.Mount(dev, e.Root, format, 0, "") },
+		"mounting %q at %q", dev, e.Root,
+	)
+}
+
+// mapImageDevices maps the device paths in cfg which refer to device or its
+// partitions onto the loop device loop. Partitions may also be referred to by
+// the /dev/disk/by-partlabel and /dev/disk/by-partuuid paths of the numbered
+// partitions which cfg creates on device, and filesystems may be on the RAID
+// arrays which cfg creates from them. Any other device path is rejected, since
+// it would refer to a device of the host rather than the image. The
+// slices and mounts of cfg may be shared with the configs it was merged from,
+// so they are copied rather than modified.
+func mapImageDevices(cfg types.Config, device, loop string) (types.Config, error) {
+	parts := map[string]int{}
+	for _, disk := range cfg.Storage.Disks {
+		if util.MapDevicePath(disk.Device, device, loop) != loop {
+			continue
+		}
+		for _, part := range disk.Partitions {
+			if part.Number == 0 {
+				continue
+			}
+			if part.Label != "" {
+				parts["/dev/disk/by-partlabel/"+part.Label] = part.Number
+			}
+			if part.GUID != "" {
+				parts["/dev/disk/by-partuuid/"+strings.ToLower(part.GUID)] = part.Number
+			}
+		}
+	}
+
+	mapPath := func(path string) (string, error) {
+		if mapped := util.MapDevicePath(path, device, loop); mapped != path {
+			return mapped, nil
+		}
+		if strings.HasPrefix(path, "/dev/disk/by-partuuid/") {
+			path = strings.ToLower(path)
+		}
+		if num, ok := parts[path]; ok {
+			return loop + "p" + strconv.Itoa(num), nil
+		}
+		return "", fmt.Errorf("device %q is not on %q", path, device)
+	}
+
+	disks := make([]types.Disk, 0, len(cfg.Storage.Disks))
+	for _, disk := range cfg.Storage.Disks {
+		dev, err := mapPath(disk.Device)
+		if err != nil {
+			return types.Config{}, err
+		}
+		disk.Device = dev
+		disks = append(disks, disk)
+	}
+	cfg.Storage.Disks = disks
+
+	arrays := map[string]bool{}
+	raids := make([]types.Raid, 0, len(cfg.Storage.Raid))
+	for _, raid := range cfg.Storage.Raid {
+		devs := make([]types.Device, 0, len(raid.Devices))
+		for _, d := range raid.Devices {
+			dev, err := mapPath(string(d))
+			if err != nil {
+				return types.Config{}, err
+			}
+			devs = append(devs, types.Device(dev))
+		}
+		raid.Devices = devs
+		raids = append(raids, raid)
+		arrays["/dev/md/"+raid.Name] = true
+	}
+	cfg.Storage.Raid = raids
+
+	filesystems := make([]types.Filesystem, 0, len(cfg.Storage.Filesystems))
+	for _, fs := range cfg.Storage.Filesystems {
+		if fs.Mount != nil && !arrays[fs.Mount.Device] {
+			dev, err := mapPath(fs.Mount.Device)
+			if err != nil {
+				return types.Config{}, err
+			}
+			mount := *fs.Mount
+			mount.Device = dev
+			fs.Mount = &mount
+		}
+		filesystems = append(filesystems, fs)
+	}
+	cfg.Storage.Filesystems = filesystems
+
+	return cfg, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestMapImageDevices(t *testing.T) {
+	type in struct {
+		storage types.Storage
+	}
+	type out struct {
+		storage types.Storage
+		err     error
+	}
+
+	disk := types.Disk{
+		Device: "/dev/sda",
+		Partitions: []types.Partition{
+			{Number: 1, Label: "DATA"},
+			{Number: 2, GUID: "6A1F2F7B-D4A6-4C3D-8A2D-4A5E0F6A0E7D"},
+			{Label: "UNNUMBERED"},
+		},
+	}
+	mappedDisk := disk
+	mappedDisk.Device = "/dev/loop0"
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in: in{storage: types.Storage{
+				Disks: []types.Disk{disk},
+				Filesystems: []types.Filesystem{
+					{Name: "root", Mount: &types.Mount{Device: "/dev/sda9"}},
+					{Name: "data", Mount: &types.Mount{Device: "/dev/disk/by-partlabel/DATA"}},
+					{Name: "uuid", Mount: &types.Mount{Device: "/dev/disk/by-partuuid/6a1f2f7b-d4a6-4c3d-8a2d-4a5e0f6a0e7d"}},
+					{Name: "path", Path: func(p string) *string { return &p }("/sysroot")},
+				},
+			}},
+			out: out{storage: types.Storage{
+				Disks: []types.Disk{mappedDisk},
+				Raid:  []types.Raid{},
+				Filesystems: []types.Filesystem{
+					{Name: "root", Mount: &types.Mount{Device: "/dev/loop0p9"}},
+					{Name: "data", Mount: &types.Mount{Device: "/dev/loop0p1"}},
+					{Name: "uuid", Mount: &types.Mount{Device: "/dev/loop0p2"}},
+					{Name: "path", Path: func(p string) *string { return &p }("/sysroot")},
+				},
+			}},
+		},
+		{
+			in: in{storage: types.Storage{
+				Raid: []types.Raid{{Name: "md", Devices: []types.Device{"/dev/sda1", "/dev/sda2"}}},
+				Filesystems: []types.Filesystem{
+					{Name: "md", Mount: &types.Mount{Device: "/dev/md/md"}},
+				},
+			}},
+			out: out{storage: types.Storage{
+				Disks: []types.Disk{},
+				Raid:  []types.Raid{{Name: "md", Devices: []types.Device{"/dev/loop0p1", "/dev/loop0p2"}}},
+				Filesystems: []types.Filesystem{
+					{Name: "md", Mount: &types.Mount{Device: "/dev/md/md"}},
+				},
+			}},
+		},
+		{
+			in: in{storage: types.Storage{
+				Disks: []types.Disk{{Device: "/dev/sdb"}},
+			}},
+			out: out{err: errors.New(`device "/dev/sdb" is not on "/dev/sda"`)},
+		},
+		{
+			in: in{storage: types.Storage{
+				Raid: []types.Raid{{Name: "md", Devices: []types.Device{"/dev/sda1", "/dev/sdb1"}}},
+			}},
+			out: out{err: errors.New(`device "/dev/sdb1" is not on "/dev/sda"`)},
+		},
+		{
+			in: in{storage: types.Storage{
+				Disks: []types.Disk{disk},
+				Filesystems: []types.Filesystem{
+					{Name: "root", Mount: &types.Mount{Device: "/dev/disk/by-label/ROOT"}},
+				},
+			}},
+			out: out{err: errors.New(`device "/dev/disk/by-label/ROOT" is not on "/dev/sda"`)},
+		},
+		{
+			in: in{storage: types.Storage{
+				Disks: []types.Disk{disk},
+				Filesystems: []types.Filesystem{
+					{Name: "root", Mount: &types.Mount{Device: "/dev/disk/by-partlabel/UNNUMBERED"}},
+				},
+			}},
+			out: out{err: errors.New(`device "/dev/disk/by-partlabel/UNNUMBERED" is not on "/dev/sda"`)},
+		},
+	}
+
+	for i, test := range tests {
+		cfg, err := mapImageDevices(types.Config{Storage: test.in.storage}, "/dev/sda", "/dev/loop0")
+		if !reflect.DeepEqual(test.out.err, err) {
+			t.Errorf("#%d: bad error: want %v, got %v", i, test.out.err, err)
+		}
+		if test.out.err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(test.out.storage, cfg.Storage) {
+			t.Errorf("#%d: bad storage: want %+v, got %+v", i, test.out.storage, cfg.Storage)
+		}
+	}
+}
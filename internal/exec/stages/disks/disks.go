@@ -41,13 +41,17 @@ func init() {
 
 type creator struct{}
 
-func (creator) Create(logger *log.Logger, client *resource.HttpClient, root string) stages.Stage {
+func (creator) Create(logger *log.Logger, client *resource.HttpClient, root string, waitOnDevices util.WaitOnDevicesFunc) stages.Stage {
+	if waitOnDevices == nil {
+		waitOnDevices = systemd.WaitOnDevices
+	}
 	return &stage{
 		Util: util.Util{
 			DestDir: root,
 			Logger:  logger,
 		},
-		client: client,
+		client:            client,
+		waitOnDevicesFunc: waitOnDevices,
 	}
 }
 
@@ -58,7 +62,8 @@ func (creator) Name() string {
 type stage struct {
 	util.Util
 
-	client *resource.HttpClient
+	client            *resource.HttpClient
+	waitOnDevicesFunc util.WaitOnDevicesFunc
 }
 
 func (stage) Name() string {
@@ -84,11 +89,20 @@ func (s stage) Run(config types.Config) bool {
 	return true
 }
 
+// Plan runs the stage with the logger in plan mode. The partitions and RAID
+// arrays to be created aren't known to the system yet, so any filesystem on
+// them is assumed to need formatting.
+func (s stage) Plan(config types.Config) ([]string, bool) {
+	s.Logger.StartPlan()
+	ok := s.Run(config)
+	return s.Logger.StopPlan(), ok
+}
+
 // waitOnDevices waits for the devices enumerated in devs as a logged operation
 // using ctxt for the logging and systemd unit identity.
 func (s stage) waitOnDevices(devs []string, ctxt string) error {
 	if err := s.LogOp(
-		func() error { return systemd.WaitOnDevices(devs, ctxt) },
+		func() error { return s.waitOnDevicesFunc(devs, ctxt) },
 		"waiting for devices %v", devs,
 	); err != nil {
 		return fmt.Errorf("failed to wait on %s devs: %v", ctxt, err)
@@ -97,8 +111,13 @@ func (s stage) waitOnDevices(devs []string, ctxt string) error {
 	return nil
 }
 
-// createDeviceAliases creates device aliases for every device in devs.
+// createDeviceAliases creates device aliases for every device in devs. The
+// aliases are only used internally, so none are created when planning.
 func (s stage) createDeviceAliases(devs []string) error {
+	if s.Logger.Planning() {
+		return nil
+	}
+
 	for _, dev := range devs {
 		target, err := util.CreateDeviceAlias(dev)
 		if err != nil {
@@ -143,31 +162,28 @@ func (s stage) createPartitions(config types.Config) error {
 	for _, dev := range config.Storage.Disks {
 		devAlias := util.DeviceAlias(string(dev.Device))
 
-		err := s.Logger.LogOp(func() error {
-			op := sgdisk.Begin(s.Logger, devAlias)
-			if dev.WipeTable {
-				s.Logger.Info("wiping partition table requested on %q", devAlias)
-				op.WipeTable(true)
-			}
+		// The sgdisk commands are logged as operations of their own, so that
+		// they are recorded when planning.
+		s.Logger.Info("partitioning %q", devAlias)
+		op := sgdisk.Begin(s.Logger, devAlias)
+		if dev.WipeTable {
+			s.Logger.Info("wiping partition table requested on %q", devAlias)
+			op.WipeTable(true)
+		}
 
-			for _, part := range dev.Partitions {
-				op.CreatePartition(sgdisk.Partition{
-					Number:   part.Number,
-					Length:   uint64(part.Size),
-					Offset:   uint64(part.Start),
-					Label:    string(part.Label),
-					TypeGUID: string(part.TypeGUID),
-					GUID:     string(part.GUID),
-				})
-			}
+		for _, part := range dev.Partitions {
+			op.CreatePartition(sgdisk.Partition{
+				Number:   part.Number,
+				Length:   uint64(part.Size),
+				Offset:   uint64(part.Start),
+				Label:    string(part.Label),
+				TypeGUID: string(part.TypeGUID),
+				GUID:     string(part.GUID),
+			})
+		}
 
-			if err := op.Commit(); err != nil {
-				return fmt.Errorf("commit failure: %v", err)
-			}
-			return nil
-		}, "partitioning %q", devAlias)
-		if err != nil {
-			return err
+		if err := op.Commit(); err != nil {
+			return fmt.Errorf("partitioning %q failed: commit failure: %v", devAlias, err)
 		}
 	}
 
@@ -287,13 +303,16 @@ func (s stage) createFilesystems(config types.Config) error {
 func (s stage) createFilesystem(fs types.Mount) error {
 	info, err := s.readFilesystemInfo(fs)
 	if err != nil {
-		return err
+		if !s.Logger.Planning() {
+			return err
+		}
+		s.Logger.Info("unable to inspect %q, assuming it needs formatting: %v", fs.Device, err)
 	}
 	if info.format == fs.Format &&
 		(fs.Label == nil || info.label == *fs.Label) &&
 		(fs.UUID == nil || info.uuid == *fs.UUID) &&
 		!fs.WipeFilesystem {
-		s.Logger.Info("filesystem at %q is already formatted. Skipping mkfs...", fs.Device)
+		s.Logger.LogSkip("filesystem at %q is already formatted. Skipping mkfs...", fs.Device)
 		return nil
 	}
 
@@ -387,8 +406,8 @@ type filesystemInfo struct {
 
 func (s stage) readFilesystemInfo(fs types.Mount) (filesystemInfo, error) {
 	res := filesystemInfo{}
-	err := s.Logger.LogOp(
-		func() error {
+	err := s.Logger.Inspect(func() error {
+		return s.Logger.LogOp(func() error {
 			var err error
 			res.format, err = util.FilesystemType(fs.Device)
 			if err != nil {
@@ -404,9 +423,8 @@ func (s stage) readFilesystemInfo(fs types.Mount) (filesystemInfo, error) {
 			}
 			s.Logger.Info("found %s filesystem at %q with uuid %q and label %q", res.format, fs.Device, res.uuid, res.label)
 			return nil
-		},
-		"determining filesystem type of %q", fs.Device,
-	)
+		}, "determining filesystem type of %q", fs.Device)
+	})
 
 	return res, err
 }
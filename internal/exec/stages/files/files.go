@@ -36,6 +36,8 @@ const (
 
 var (
 	ErrFilesystemUndefined = errors.New("the referenced filesystem was not defined")
+	ErrResolveFile         = errors.New("failed to resolve the contents of the file")
+	ErrFilesystemUnmounted = errors.New("the filesystem is not mounted at its path; the mount stage needs to run first")
 )
 
 func init() {
@@ -44,7 +46,7 @@ func init() {
 
 type creator struct{}
 
-func (creator) Create(logger *log.Logger, client *resource.HttpClient, root string) stages.Stage {
+func (creator) Create(logger *log.Logger, client *resource.HttpClient, root string, _ util.WaitOnDevicesFunc) stages.Stage {
 	return &stage{
 		Util: util.Util{
 			DestDir: root,
@@ -87,7 +89,17 @@ func (s stage) Run(config types.Config) bool {
 	return true
 }
 
-// createFilesystemsEntries creates the files described in config.Storage.{Files,Directories}.
+// Plan runs the stage with the logger in plan mode. The contents of files
+// aren't fetched, and the owners of entries can only be resolved for users and
+// groups which already exist.
+func (s stage) Plan(config types.Config) ([]string, bool) {
+	s.Logger.StartPlan()
+	ok := s.Run(config)
+	return s.Logger.StopPlan(), ok
+}
+
+// createFilesystemsEntries removes the nodes listed in config.Storage.Remove and creates the files described in
+// config.Storage.{Files,Directories,Links}.
 func (s stage) createFilesystemsEntries(config types.Config) error {
 	if len(config.Storage.Filesystems) == 0 {
 		return nil
@@ -114,20 +126,24 @@ type filesystemEntry interface {
 	create(l *log.Logger, c *resource.HttpClient, u util.Util) error
 }
 
-type fileEntry types.File
+type fileEntry struct {
+	types.File
+	keys util.Keyring
+}
 
 func (tmp fileEntry) create(l *log.Logger, c *resource.HttpClient, u util.Util) error {
-	f := types.File(tmp)
-	file := util.RenderFile(l, c, f)
-	if file == nil {
-		return fmt.Errorf("failed to resolve file %q", f.Path)
-	}
-
-	if err := l.LogOp(
-		func() error { return u.WriteFile(file) },
-		"writing file %q", string(f.Path),
-	); err != nil {
-		return fmt.Errorf("failed to create file %q: %v", file.Path, err)
+	f := tmp.File
+
+	// The contents are fetched as part of the operation, so that they aren't
+	// fetched when planning.
+	if err := l.LogOp(func() error {
+		file := util.RenderFile(l, c, f, tmp.keys)
+		if file == nil {
+			return ErrResolveFile
+		}
+		return u.WriteFile(file)
+	}, "writing file %q", string(f.Path)); err != nil {
+		return fmt.Errorf("failed to create file %q: %v", f.Path, err)
 	}
 
 	return nil
@@ -140,6 +156,19 @@ func (tmp dirEntry) create(l *log.Logger, _ *resource.HttpClient, u util.Util) e
 	err := l.LogOp(func() error {
 		path := filepath.Clean(u.JoinPath(string(d.Path)))
 
+		// An existing directory (or link to one) is kept as is. Anything else
+		// at the path is only replaced if the config allows it.
+		if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+			if target, err := os.Stat(path); err != nil || !target.IsDir() {
+				if !d.ShouldOverwrite() {
+					return util.ErrNodeExists
+				}
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+			}
+		}
+
 		// Build a list of paths to create. Since os.MkdirAll only sets the mode for new directories and not the
 		// ownership, we need to determine which directories will be created so we don't chown something that already
 		// exists.
@@ -191,6 +220,21 @@ func (tmp linkEntry) create(l *log.Logger, _ *resource.HttpClient, u util.Util)
 	return nil
 }
 
+type removeEntry types.Removal
+
+func (tmp removeEntry) create(l *log.Logger, _ *resource.HttpClient, u util.Util) error {
+	rm := types.Removal(tmp)
+
+	if err := l.LogOp(
+		func() error { return u.RemoveNode(rm) },
+		"removing %q", rm.Path,
+	); err != nil {
+		return fmt.Errorf("failed to remove %q: %v", rm.Path, err)
+	}
+
+	return nil
+}
+
 // ByDirectorySegments is used to sort directories so /foo gets created before /foo/bar if they are both specified.
 type ByDirectorySegments []types.Directory
 
@@ -207,20 +251,39 @@ func (lst ByDirectorySegments) Less(i, j int) bool {
 // mapEntriesToFilesystems builds a map of filesystems to files. If multiple
 // definitions of the same filesystem are present, only the final definition is
 // used. The directories are sorted to ensure /foo gets created before /foo/bar.
+// The owners of all entries are resolved to IDs (see resolveOwner).
 func (s stage) mapEntriesToFilesystems(config types.Config) (map[types.Filesystem][]filesystemEntry, error) {
 	filesystems := map[string]types.Filesystem{}
 	for _, fs := range config.Storage.Filesystems {
 		filesystems[fs.Name] = fs
 	}
 
+	keys, err := util.NewKeyring(config.Ignition.Security.TrustedKeys)
+	if err != nil {
+		return nil, err
+	}
+
 	entryMap := map[types.Filesystem][]filesystemEntry{}
 
+	// Add removals first so that entries can replace what they remove.
+	for _, rm := range config.Storage.Remove {
+		if fs, ok := filesystems[rm.Filesystem]; ok {
+			entryMap[fs] = append(entryMap[fs], removeEntry(rm))
+		} else {
+			s.Logger.Crit("the filesystem (%q), was not defined", rm.Filesystem)
+			return nil, ErrFilesystemUndefined
+		}
+	}
+
 	// Sort directories to ensure /a gets created before /a/b.
 	sortedDirs := config.Storage.Directories
 	sort.Sort(ByDirectorySegments(sortedDirs))
 
-	// Add directories first to ensure they are created before files.
+	// Add directories before files to ensure they are created first.
 	for _, d := range sortedDirs {
+		if d.Node, err = s.resolveOwner(d.Node); err != nil {
+			return nil, err
+		}
 		if fs, ok := filesystems[d.Filesystem]; ok {
 			entryMap[fs] = append(entryMap[fs], dirEntry(d))
 		} else {
@@ -230,8 +293,11 @@ func (s stage) mapEntriesToFilesystems(config types.Config) (map[types.Filesyste
 	}
 
 	for _, f := range config.Storage.Files {
+		if f.Node, err = s.resolveOwner(f.Node); err != nil {
+			return nil, err
+		}
 		if fs, ok := filesystems[f.Filesystem]; ok {
-			entryMap[fs] = append(entryMap[fs], fileEntry(f))
+			entryMap[fs] = append(entryMap[fs], fileEntry{File: f, keys: keys})
 		} else {
 			s.Logger.Crit("the filesystem (%q), was not defined", f.Filesystem)
 			return nil, ErrFilesystemUndefined
@@ -239,6 +305,9 @@ func (s stage) mapEntriesToFilesystems(config types.Config) (map[types.Filesyste
 	}
 
 	for _, sy := range config.Storage.Links {
+		if sy.Node, err = s.resolveOwner(sy.Node); err != nil {
+			return nil, err
+		}
 		if fs, ok := filesystems[sy.Filesystem]; ok {
 			entryMap[fs] = append(entryMap[fs], linkEntry(sy))
 		} else {
@@ -250,13 +319,47 @@ func (s stage) mapEntriesToFilesystems(config types.Config) (map[types.Filesyste
 	return entryMap, nil
 }
 
+// resolveOwner resolves the user and group of n to IDs. Names are looked up in
+// the root filesystem, which holds the users and groups created by the passwd
+// section of the config.
+func (s stage) resolveOwner(n types.Node) (types.Node, error) {
+	resolved, err := s.ResolveNodeOwner(n)
+	if err != nil && s.Logger.Planning() {
+		s.Logger.Info("unable to resolve the owner of %q, which may be created by the passwd section: %v", n.Path, err)
+		return n, nil
+	}
+	if err != nil {
+		s.Logger.Crit("failed to resolve the owner of %q: %v", n.Path, err)
+		return types.Node{}, err
+	}
+	return resolved, nil
+}
+
 // createEntries creates any files or directories listed for the filesystem in Storage.{Files,Directories}.
 func (s stage) createEntries(fs types.Filesystem, files []filesystemEntry) error {
 	s.Logger.PushPrefix("createFiles")
 	defer s.Logger.PopPrefix()
 
 	var mnt string
-	if fs.Path == nil {
+	if fs.Mount != nil && fs.Mount.Path != nil {
+		// Filesystems with a mount path are mounted by the mount stage, so
+		// the entries are written into the root's hierarchy.
+		if !s.Logger.Planning() {
+			mounted, err := s.IsMounted(*fs.Mount)
+			if err != nil {
+				return fmt.Errorf("failed to check whether %q is mounted: %v", *fs.Mount.Path, err)
+			}
+			if !mounted {
+				return ErrFilesystemUnmounted
+			}
+		}
+		mnt = s.JoinPath(*fs.Mount.Path)
+	} else if fs.Path == nil && s.Logger.Planning() {
+		// Nothing is written when planning, so there's no need to mount.
+		dev := string(fs.Mount.Device)
+		s.Logger.LogOp(nil, "mounting %q", dev)
+		defer s.Logger.LogOp(nil, "unmounting %q", dev)
+	} else if fs.Path == nil {
 		var err error
 		mnt, err = ioutil.TempDir("", "ignition-files")
 		if err != nil {
@@ -328,57 +431,50 @@ func (s stage) createUnits(config types.Config) error {
 
 // writeSystemdUnit creates the specified unit and any dropins for that unit.
 // If the contents of the unit or are empty, the unit is not created. The same
-// applies to the unit's dropins.
+// applies to the unit's dropins. Each file is written as an operation of its
+// own, so that they are recorded when planning.
 func (s stage) writeSystemdUnit(unit types.Unit) error {
-	return s.Logger.LogOp(func() error {
-		for _, dropin := range unit.Dropins {
-			if dropin.Contents == "" {
-				continue
-			}
+	s.Logger.Info("processing unit %q", unit.Name)
 
-			f := util.FileFromUnitDropin(unit, dropin)
-			if err := s.Logger.LogOp(
-				func() error { return s.WriteFile(f) },
-				"writing drop-in %q at %q", dropin.Name, f.Path,
-			); err != nil {
-				return err
-			}
+	for _, dropin := range unit.Dropins {
+		if dropin.Contents == "" {
+			continue
 		}
 
-		if unit.Contents == "" {
-			return nil
-		}
-
-		f := util.FileFromSystemdUnit(unit)
+		f := util.FileFromUnitDropin(unit, dropin)
 		if err := s.Logger.LogOp(
 			func() error { return s.WriteFile(f) },
-			"writing unit %q at %q", unit.Name, f.Path,
+			"writing drop-in %q at %q", dropin.Name, f.Path,
 		); err != nil {
 			return err
 		}
+	}
 
+	if unit.Contents == "" {
 		return nil
-	}, "processing unit %q", unit.Name)
+	}
+
+	f := util.FileFromSystemdUnit(unit)
+	return s.Logger.LogOp(
+		func() error { return s.WriteFile(f) },
+		"writing unit %q at %q", unit.Name, f.Path,
+	)
 }
 
 // writeNetworkdUnit creates the specified unit. If the contents of the unit or
 // are empty, the unit is not created.
 func (s stage) writeNetworkdUnit(unit types.Networkdunit) error {
-	return s.Logger.LogOp(func() error {
-		if unit.Contents == "" {
-			return nil
-		}
-
-		f := util.FileFromNetworkdUnit(unit)
-		if err := s.Logger.LogOp(
-			func() error { return s.WriteFile(f) },
-			"writing unit %q at %q", unit.Name, f.Path,
-		); err != nil {
-			return err
-		}
+	s.Logger.Info("processing unit %q", unit.Name)
 
+	if unit.Contents == "" {
 		return nil
-	}, "processing unit %q", unit.Name)
+	}
+
+	f := util.FileFromNetworkdUnit(unit)
+	return s.Logger.LogOp(
+		func() error { return s.WriteFile(f) },
+		"writing unit %q at %q", unit.Name, f.Path,
+	)
 }
 
 // createPasswd creates the users and groups as described in config.Passwd.
@@ -35,6 +35,8 @@ func TestMapEntriesToFilesystems(t *testing.T) {
 
 	fs1 := "/fs1"
 	fs2 := "/fs2"
+	root := types.NodeUser{ID: func(i int) *int { return &i }(0)}
+	rootGroup := types.NodeGroup{ID: func(i int) *int { return &i }(0)}
 
 	tests := []struct {
 		in  in
@@ -57,8 +59,8 @@ func TestMapEntriesToFilesystems(t *testing.T) {
 				},
 			}}},
 			out: out{files: map[types.Filesystem][]filesystemEntry{{Name: "fs1"}: {
-				fileEntry(types.File{Node: types.Node{Filesystem: "fs1", Path: "/foo"}}),
-				fileEntry(types.File{Node: types.Node{Filesystem: "fs1", Path: "/bar"}}),
+				fileEntry{File: types.File{Node: types.Node{Filesystem: "fs1", Path: "/foo", User: root, Group: rootGroup}}},
+				fileEntry{File: types.File{Node: types.Node{Filesystem: "fs1", Path: "/bar", User: root, Group: rootGroup}}},
 			}}},
 		},
 		{
@@ -70,8 +72,8 @@ func TestMapEntriesToFilesystems(t *testing.T) {
 				},
 			}}},
 			out: out{files: map[types.Filesystem][]filesystemEntry{
-				{Name: "fs1", Path: &fs1}: {fileEntry(types.File{Node: types.Node{Filesystem: "fs1", Path: "/foo"}})},
-				{Name: "fs2", Path: &fs2}: {fileEntry(types.File{Node: types.Node{Filesystem: "fs2", Path: "/bar"}})},
+				{Name: "fs1", Path: &fs1}: {fileEntry{File: types.File{Node: types.Node{Filesystem: "fs1", Path: "/foo", User: root, Group: rootGroup}}}},
+				{Name: "fs2", Path: &fs2}: {fileEntry{File: types.File{Node: types.Node{Filesystem: "fs2", Path: "/bar", User: root, Group: rootGroup}}}},
 			}},
 		},
 		{
@@ -84,11 +86,28 @@ func TestMapEntriesToFilesystems(t *testing.T) {
 			}}},
 			out: out{files: map[types.Filesystem][]filesystemEntry{
 				{Name: "fs1", Path: &fs1}: {
-					fileEntry(types.File{Node: types.Node{Filesystem: "fs1", Path: "/foo"}}),
-					fileEntry(types.File{Node: types.Node{Filesystem: "fs1", Path: "/bar"}}),
+					fileEntry{File: types.File{Node: types.Node{Filesystem: "fs1", Path: "/foo", User: root, Group: rootGroup}}},
+					fileEntry{File: types.File{Node: types.Node{Filesystem: "fs1", Path: "/bar", User: root, Group: rootGroup}}},
 				},
 			}},
 		},
+		{
+			in: in{config: types.Config{Storage: types.Storage{
+				Filesystems: []types.Filesystem{{Name: "fs1", Path: &fs1}},
+				Files:       []types.File{{Node: types.Node{Filesystem: "fs1", Path: "/foo"}}},
+				Remove:      []types.Removal{{Filesystem: "fs1", Path: "/foo"}},
+			}}},
+			out: out{files: map[types.Filesystem][]filesystemEntry{
+				{Name: "fs1", Path: &fs1}: {
+					removeEntry{Filesystem: "fs1", Path: "/foo"},
+					fileEntry{File: types.File{Node: types.Node{Filesystem: "fs1", Path: "/foo", User: root, Group: rootGroup}}},
+				},
+			}},
+		},
+		{
+			in:  in{config: types.Config{Storage: types.Storage{Remove: []types.Removal{{Filesystem: "foo", Path: "/bar"}}}}},
+			out: out{err: ErrFilesystemUndefined},
+		},
 	}
 
 	for i, test := range tests {
@@ -145,3 +164,49 @@ func TestDirectorySort(t *testing.T) {
 		}
 	}
 }
+
+func TestPlanUnits(t *testing.T) {
+	type in struct {
+		config types.Config
+	}
+	type out struct {
+		ops []string
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in: in{config: types.Config{
+				Systemd: types.Systemd{Units: []types.Unit{
+					{
+						Name:     "a.service",
+						Contents: "[Service]",
+						Dropins:  []types.Dropin{{Name: "10-a.conf", Contents: "[Service]"}, {Name: "20-empty.conf"}},
+					},
+					{Name: "b.service", Dropins: []types.Dropin{{Name: "10-b.conf", Contents: "[Unit]"}}},
+				}},
+				Networkd: types.Networkd{Units: []types.Networkdunit{{Name: "c.network", Contents: "[Match]"}}},
+			}},
+			out: out{ops: []string{
+				`files: writing drop-in "10-a.conf" at "etc/systemd/system/a.service.d/10-a.conf"`,
+				`files: writing unit "a.service" at "etc/systemd/system/a.service"`,
+				`files: writing drop-in "10-b.conf" at "etc/systemd/system/b.service.d/10-b.conf"`,
+				`files: writing unit "c.network" at "etc/systemd/network/c.network"`,
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		logger := log.New()
+		logger.PushPrefix("files")
+		ops, ok := creator{}.Create(&logger, nil, "/nonexistent", nil).Plan(test.in.config)
+		if !ok {
+			t.Errorf("#%d: planning failed", i)
+		}
+		if !reflect.DeepEqual(test.out.ops, ops) {
+			t.Errorf("#%d: bad ops: want %q, got %q", i, test.out.ops, ops)
+		}
+	}
+}
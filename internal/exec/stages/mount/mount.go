@@ -0,0 +1,129 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The mount stage is responsible for mounting the filesystems which have a
+// mount path at that path under the root, so that the files stage and the
+// provisioned system's units can use them.
+
+package mount
+
+import (
+	"fmt"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/exec/stages"
+	"github.com/coreos/ignition/internal/exec/util"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+	"github.com/coreos/ignition/internal/systemd"
+)
+
+const (
+	name = "mount"
+)
+
+func init() {
+	stages.Register(creator{})
+}
+
+type creator struct{}
+
+func (creator) Create(logger *log.Logger, client *resource.HttpClient, root string, waitOnDevices util.WaitOnDevicesFunc) stages.Stage {
+	if waitOnDevices == nil {
+		waitOnDevices = systemd.WaitOnDevices
+	}
+	return &stage{
+		Util: util.Util{
+			DestDir: root,
+			Logger:  logger,
+		},
+		waitOnDevicesFunc: waitOnDevices,
+	}
+}
+
+func (creator) Name() string {
+	return name
+}
+
+type stage struct {
+	util.Util
+
+	waitOnDevicesFunc util.WaitOnDevicesFunc
+}
+
+func (stage) Name() string {
+	return name
+}
+
+func (s stage) Run(config types.Config) bool {
+	if err := s.mountFilesystems(config); err != nil {
+		s.Logger.Crit("failed to mount filesystems: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// Plan runs the stage with the logger in plan mode.
+func (s stage) Plan(config types.Config) ([]string, bool) {
+	s.Logger.StartPlan()
+	ok := s.Run(config)
+	return s.Logger.StopPlan(), ok
+}
+
+// mountFilesystems mounts the filesystems described in config.Storage.Filesystems
+// which have a mount path, parents before their children. Filesystems which
+// are already mounted at their path are left as they are.
+func (s stage) mountFilesystems(config types.Config) error {
+	fss := util.MountedFilesystems(config)
+	if len(fss) == 0 {
+		return nil
+	}
+	s.Logger.PushPrefix("mountFilesystems")
+	defer s.Logger.PopPrefix()
+
+	devs := []string{}
+	for _, fs := range fss {
+		devs = append(devs, fs.Mount.Device)
+	}
+
+	if err := s.LogOp(
+		func() error { return s.waitOnDevicesFunc(devs, "mount") },
+		"waiting for devices %v", devs,
+	); err != nil {
+		return fmt.Errorf("failed to wait on mount devs: %v", err)
+	}
+
+	for _, fs := range fss {
+		m := *fs.Mount
+
+		mounted, err := s.IsMounted(m)
+		if err != nil {
+			return fmt.Errorf("failed to check whether %q is mounted: %v", *m.Path, err)
+		}
+		if mounted {
+			s.Logger.LogSkip("a filesystem is already mounted at %q", *m.Path)
+			continue
+		}
+
+		if err := s.LogOp(
+			func() error { return s.MountFilesystem(m) },
+			"mounting %q at %q", m.Device, *m.Path,
+		); err != nil {
+			return fmt.Errorf("failed to mount %q: %v", m.Device, err)
+		}
+	}
+
+	return nil
+}
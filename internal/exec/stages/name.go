@@ -28,7 +28,7 @@ func (s Name) String() string {
 }
 
 func (s *Name) Set(val string) error {
-	if stage := Get(val); stage == nil {
+	if stage := Get(val); stage == nil && val != FetchName {
 		return fmt.Errorf("%s is not a valid stage", val)
 	}
 
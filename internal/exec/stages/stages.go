@@ -16,6 +16,7 @@ package stages
 
 import (
 	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/exec/util"
 	"github.com/coreos/ignition/internal/log"
 	"github.com/coreos/ignition/internal/registry"
 	"github.com/coreos/ignition/internal/resource"
@@ -24,16 +25,25 @@ import (
 // Stage is responsible for actually executing a stage of the configuration.
 type Stage interface {
 	Run(config types.Config) bool
+	// Plan returns the operations which Run would perform for config, in
+	// order, without performing them. The system is only inspected.
+	Plan(config types.Config) ([]string, bool)
 	Name() string
 }
 
 // StageCreator is responsible for instantiating a particular stage given a
-// logger and root path under the root partition.
+// logger and root path under the root partition. Stages wait for devices
+// using waitOnDevices, or systemd if it is nil.
 type StageCreator interface {
-	Create(logger *log.Logger, client *resource.HttpClient, root string) Stage
+	Create(logger *log.Logger, client *resource.HttpClient, root string, waitOnDevices util.WaitOnDevicesFunc) Stage
 	Name() string
 }
 
+// FetchName is the name of the stage which acquires the config and caches it
+// for the other stages. It is run by the engine itself, since there is no
+// config for it to apply yet, and so isn't registered.
+const FetchName = "fetch"
+
 var stages = registry.Create("stages")
 
 func Register(stage StageCreator) {
@@ -48,5 +58,5 @@ func Get(name string) StageCreator {
 }
 
 func Names() (names []string) {
-	return stages.Names()
+	return append([]string{FetchName}, stages.Names()...)
 }
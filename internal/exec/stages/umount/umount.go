@@ -0,0 +1,109 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The umount stage is responsible for unmounting the filesystems mounted by
+// the mount stage.
+
+package umount
+
+import (
+	"fmt"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/exec/stages"
+	"github.com/coreos/ignition/internal/exec/util"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+)
+
+const (
+	name = "umount"
+)
+
+func init() {
+	stages.Register(creator{})
+}
+
+type creator struct{}
+
+func (creator) Create(logger *log.Logger, _ *resource.HttpClient, root string, _ util.WaitOnDevicesFunc) stages.Stage {
+	return &stage{
+		Util: util.Util{
+			DestDir: root,
+			Logger:  logger,
+		},
+	}
+}
+
+func (creator) Name() string {
+	return name
+}
+
+type stage struct {
+	util.Util
+}
+
+func (stage) Name() string {
+	return name
+}
+
+func (s stage) Run(config types.Config) bool {
+	if err := s.unmountFilesystems(config); err != nil {
+		s.Logger.Crit("failed to unmount filesystems: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// Plan runs the stage with the logger in plan mode.
+func (s stage) Plan(config types.Config) ([]string, bool) {
+	s.Logger.StartPlan()
+	ok := s.Run(config)
+	return s.Logger.StopPlan(), ok
+}
+
+// unmountFilesystems unmounts the filesystems described in
+// config.Storage.Filesystems which have a mount path, children before their
+// parents. Paths which nothing is mounted at are skipped.
+func (s stage) unmountFilesystems(config types.Config) error {
+	fss := util.MountedFilesystems(config)
+	if len(fss) == 0 {
+		return nil
+	}
+	s.Logger.PushPrefix("unmountFilesystems")
+	defer s.Logger.PopPrefix()
+
+	for i := len(fss) - 1; i >= 0; i-- {
+		m := *fss[i].Mount
+
+		mounted, err := s.IsMounted(m)
+		if err != nil {
+			return fmt.Errorf("failed to check whether %q is mounted: %v", *m.Path, err)
+		}
+		if !mounted {
+			s.Logger.LogSkip("nothing is mounted at %q", *m.Path)
+			continue
+		}
+
+		if err := s.LogOp(
+			func() error { return s.UnmountFilesystem(m) },
+			"unmounting %q at %q", m.Device, *m.Path,
+		); err != nil {
+			return fmt.Errorf("failed to unmount %q: %v", *m.Path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+)
+
+const (
+	xzCmd   = "xz"
+	zstdCmd = "zstd"
+)
+
+// gzipReader is a wrapper for gzip's reader that closes the stream it wraps as well
+// as itself when Close() is called.
+type gzipReader struct {
+	*gzip.Reader //actually a ReadCloser
+	source       io.Closer
+}
+
+func newGzipReader(reader io.ReadCloser) (io.ReadCloser, error) {
+	gzReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	return gzipReader{
+		Reader: gzReader,
+		source: reader,
+	}, nil
+}
+
+func (gz gzipReader) Close() error {
+	if err := gz.Reader.Close(); err != nil {
+		return err
+	}
+	if err := gz.source.Close(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// cmdReader streams the contents of source through an external decompressor
+// (e.g. `xz -dc`), for formats without a decompressor in the standard
+// library. The decompressor's exit status is checked once its output has been
+// read, so that a corrupt stream surfaces as a read error rather than a
+// truncated file.
+type cmdReader struct {
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+	stderr bytes.Buffer
+	source io.Closer
+	done   bool
+}
+
+func newCmdReader(reader io.ReadCloser, name string, args ...string) (io.ReadCloser, error) {
+	// The decompressors aren't part of every initramfs, so fail with a clear
+	// error rather than exec's if the config uses one which is missing.
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing requires the %q command, which was not found", name)
+	}
+
+	r := &cmdReader{
+		cmd:    exec.Command(path, args...),
+		source: reader,
+	}
+	r.cmd.Stdin = reader
+	r.cmd.Stderr = &r.stderr
+
+	if r.stdout, err = r.cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	if err := r.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *cmdReader) Read(p []byte) (int, error) {
+	n, err := r.stdout.Read(p)
+	if err == io.EOF {
+		if werr := r.wait(); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+func (r *cmdReader) wait() error {
+	if r.done {
+		return nil
+	}
+	r.done = true
+	if err := r.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", r.cmd.Args[0], err, strings.TrimSpace(r.stderr.String()))
+	}
+	return nil
+}
+
+// Close stops the decompressor if its output wasn't read to the end. The
+// source is closed first so that a pending read from it (e.g. an HTTP body)
+// doesn't hold up the decompressor's exit.
+func (r *cmdReader) Close() error {
+	err := r.source.Close()
+	if !r.done {
+		r.done = true
+		r.cmd.Process.Kill()
+		r.cmd.Wait()
+	}
+	return err
+}
+
+func decompressFileStream(l *log.Logger, fc types.FileContents, contents io.ReadCloser) (io.ReadCloser, error) {
+	switch fc.Compression {
+	case "":
+		return contents, nil
+	case "gzip":
+		return newGzipReader(contents)
+	case "bzip2":
+		return struct {
+			io.Reader
+			io.Closer
+		}{
+			Reader: bzip2.NewReader(contents),
+			Closer: contents,
+		}, nil
+	case "xz":
+		return newCmdReader(contents, xzCmd, "--decompress", "--stdout")
+	case "zstd":
+		return newCmdReader(contents, zstdCmd, "--decompress", "--stdout")
+	default:
+		return nil, types.ErrCompressionInvalid
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os/exec"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestDecompressFileStream(t *testing.T) {
+	type in struct {
+		compression string
+		data        string // base64
+	}
+	type out struct {
+		data string
+		err  bool
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{compression: "", data: "aGVsbG8gd29ybGQK"},
+			out: out{data: "hello world\n"},
+		},
+		{
+			in:  in{compression: "gzip", data: "H4sIAAAAAAAAA8tIzcnJVyjPL8pJ4QIALTsIrwwAAAA="},
+			out: out{data: "hello world\n"},
+		},
+		{
+			in:  in{compression: "bzip2", data: "QlpoOTFBWSZTWU7s6DYAAAJRgAAQQAAGRJCAIAAxBkxBAaeppYC7lDH4u5IpwoSCd2dBsA=="},
+			out: out{data: "hello world\n"},
+		},
+		{
+			in:  in{compression: "xz", data: "/Td6WFoAAATm1rRGBMAQDCEBFgAAAAAAAAAAAHuwVCgBAAtoZWxsbyB3b3JsZAoAofL/xGp/v88AASwMrpIBEB+2830BAAAAAARZWg=="},
+			out: out{data: "hello world\n"},
+		},
+		{
+			in:  in{compression: "zstd", data: "KLUv/QRYYQAAaGVsbG8gd29ybGQKjG19IA=="},
+			out: out{data: "hello world\n"},
+		},
+		{
+			in:  in{compression: "xz", data: "aGVsbG8gd29ybGQK"},
+			out: out{err: true},
+		},
+		{
+			in:  in{compression: "zstd", data: "aGVsbG8gd29ybGQK"},
+			out: out{err: true},
+		},
+		{
+			in:  in{compression: "lzma", data: "aGVsbG8gd29ybGQK"},
+			out: out{err: true},
+		},
+	}
+
+	for i, test := range tests {
+		switch test.in.compression {
+		case "xz", "zstd":
+			if _, err := exec.LookPath(test.in.compression); err != nil {
+				t.Logf("#%d: skipping, %s not found", i, test.in.compression)
+				continue
+			}
+		}
+
+		compressed, err := base64.StdEncoding.DecodeString(test.in.data)
+		if err != nil {
+			t.Fatalf("#%d: bad test data: %v", i, err)
+		}
+		fc := types.FileContents{Compression: test.in.compression}
+
+		var data []byte
+		reader, err := decompressFileStream(nil, fc, ioutil.NopCloser(bytes.NewReader(compressed)))
+		if err == nil {
+			data, err = ioutil.ReadAll(reader)
+			reader.Close()
+		}
+		if test.out.err != (err != nil) {
+			t.Errorf("#%d: bad err: want %t, got %v", i, test.out.err, err)
+			continue
+		}
+		if !test.out.err && string(data) != test.out.data {
+			t.Errorf("#%d: bad data: want %q, got %q", i, test.out.data, data)
+		}
+	}
+}
+
+func TestNewCmdReaderMissing(t *testing.T) {
+	_, err := newCmdReader(ioutil.NopCloser(bytes.NewReader(nil)), "ignition-no-such-decompressor")
+	want := `decompressing requires the "ignition-no-such-decompressor" command, which was not found`
+	if err == nil || err.Error() != want {
+		t.Errorf("bad err: want %q, got %v", want, err)
+	}
+}
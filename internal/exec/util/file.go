@@ -16,16 +16,17 @@ package util
 
 import (
 	"bufio"
-	"compress/gzip"
-	"encoding/hex"
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"hash"
 	"io"
 	"io/ioutil"
 	"net/url"
 	"os"
 	"path/filepath"
-	"strconv"
 
+	"github.com/coreos/ignition/config"
 	"github.com/coreos/ignition/config/types"
 	"github.com/coreos/ignition/internal/log"
 	"github.com/coreos/ignition/internal/resource"
@@ -33,35 +34,58 @@ import (
 	"golang.org/x/net/context"
 )
 
+var (
+	ErrNodeExists = errors.New("a different node already exists at the path and overwrite is disabled")
+)
+
 const (
-	DefaultDirectoryPermissions os.FileMode = 0755
-	DefaultFilePermissions      os.FileMode = 0644
+	DefaultDirectoryPermissions os.FileMode = config.DefaultDirectoryMode
+	DefaultFilePermissions      os.FileMode = config.DefaultFileMode
 )
 
 type File struct {
 	io.ReadCloser
-	hash.Hash
-	Path        string
-	Mode        os.FileMode
-	Uid         int
-	Gid         int
-	expectedSum string
+	Path   string
+	Mode   os.FileMode
+	Uid    int
+	Gid    int
+	hashes []hashVerifier
+
+	// signed is set if the contents must carry a signature by one of keys.
+	signed    bool
+	signature []byte
+	sigHash   hash.Hash
+	keys      Keyring
+
+	// appends are written after the contents. If appendExisting is set, the
+	// contents are preceded by those of the existing file, if any.
+	appends        []*File
+	appendExisting bool
+
+	// overwrite is set if a different node at Path is replaced.
+	overwrite bool
 }
 
-func (f File) Verify() error {
-	if f.Hash == nil {
-		return nil
+// Close closes the contents and all of the fragments to append.
+func (f File) Close() error {
+	err := f.ReadCloser.Close()
+	for _, a := range f.appends {
+		if aerr := a.Close(); err == nil {
+			err = aerr
+		}
 	}
-	sum := f.Sum(nil)
-	encodedSum := make([]byte, hex.EncodedLen(len(sum)))
-	hex.Encode(encodedSum, sum)
+	return err
+}
 
-	if string(encodedSum) != f.expectedSum {
-		return ErrHashMismatch{
-			Calculated: string(encodedSum),
-			Expected:   f.expectedSum,
+func (f File) Verify() error {
+	for _, v := range f.hashes {
+		if err := v.verify(); err != nil {
+			return err
 		}
 	}
+	if f.signed {
+		return f.keys.verifyDigest(f.sigHash.Sum(nil), f.signature)
+	}
 	return nil
 }
 
@@ -78,134 +102,152 @@ func newHashedReader(reader io.ReadCloser, hasher hash.Hash) io.ReadCloser {
 
 // RenderFile returns a *File with a Reader that downloads, hashes, and decompresses the incoming data.
 // It returns nil if f had invalid options. Errors reading/verifying/decompressing the file will
-// present themselves when the Reader is actually read from.
-func RenderFile(l *log.Logger, c *resource.HttpClient, f types.File) *File {
+// present themselves when the Reader is actually read from. If a signature is requested, it is
+// fetched up front and checked against keys by Verify. Each of the fragments to append is rendered
+// the same way. The file is owned by the user and group IDs of f (see ResolveNodeOwner).
+func RenderFile(l *log.Logger, c *resource.HttpClient, f types.File, keys Keyring) *File {
+	file := renderContents(l, c, f.Path, f.Contents, keys)
+	if file == nil {
+		return nil
+	}
+	for _, contents := range f.Append {
+		fragment := renderContents(l, c, f.Path, contents, keys)
+		if fragment == nil {
+			file.Close()
+			return nil
+		}
+		file.appends = append(file.appends, fragment)
+	}
+	file.appendExisting = f.Contents.Source == "" && len(f.Append) > 0
+
+	file.Path = f.Path
+	file.Mode = os.FileMode(f.Mode)
+	file.overwrite = f.ShouldOverwrite()
+	file.Uid = idOrRoot(f.User.ID)
+	file.Gid = idOrRoot(f.Group.ID)
+	return file
+}
+
+// renderContents returns a *File with a Reader for contents, which belong to
+// the file at path. Only the Reader and what is needed to Verify it are set.
+func renderContents(l *log.Logger, c *resource.HttpClient, path string, contents types.FileContents, keys Keyring) *File {
 	var reader io.ReadCloser
 	var err error
-	var expectedSum string
+	var signature []byte
+	var sigHash hash.Hash
 
 	// explicitly ignoring the error here because the config should already be
 	// validated by this point
-	u, _ := url.Parse(f.Contents.Source)
+	u, _ := url.Parse(contents.Source)
 
 	reader, err = resource.FetchAsReader(l, c, context.Background(), *u)
 	if err != nil {
-		l.Crit("Error fetching file %q: %v", f.Path, err)
+		l.Crit("Error fetching file %q: %v", path, err)
 		return nil
 	}
 
-	fileHash, err := GetHasher(f.Contents.Verification)
+	hashes, err := getHashVerifiers(contents.Verification)
 	if err != nil {
-		l.Crit("Error verifying file %q: %v", f.Path, err)
+		l.Crit("Error verifying file %q: %v", path, err)
+		reader.Close()
 		return nil
 	}
-
-	if fileHash != nil {
-		reader = newHashedReader(reader, fileHash)
-		// explicitly ignoring the error here because the config should already
-		// be validated by this point
-		_, expectedSum, _ = f.Contents.Verification.HashParts()
-	}
-
-	reader, err = decompressFileStream(l, f, reader)
-	if err != nil {
-		l.Crit("Error decompressing file %q: %v", f.Path, err)
-		return nil
+	for _, v := range hashes {
+		reader = newHashedReader(reader, v.Hash)
 	}
 
-	if f.User.Name != "" {
-		u, err := Util{DestDir: "/sysroot"}.userLookup(f.User.Name)
-		if err != nil {
-			l.Crit("No such user %q: %v", f.User.Name, err)
-			return nil
-		}
-		uid, err := strconv.ParseInt(u.Uid, 0, 0)
+	signed := contents.Verification.Signature != nil
+	if signed {
+		signature, err = FetchSignature(l, c, contents.Verification, contents.Source)
 		if err != nil {
-			l.Crit("Couldn't parse uid %q: %v", u.Uid, err)
+			l.Crit("Error fetching signature of file %q: %v", path, err)
+			reader.Close()
 			return nil
 		}
-		tmp := int(uid)
-		f.User.ID = &tmp
+		sigHash = sha256.New()
+		reader = newHashedReader(reader, sigHash)
 	}
-	if f.Group.Name != "" {
-		g, err := Util{DestDir: "/sysroot"}.groupLookup(f.Group.Name)
-		if err != nil {
-			l.Crit("No such group %q: %v", f.Group.Name, err)
-			return nil
-		}
-		gid, err := strconv.ParseInt(g.Gid, 0, 0)
-		if err != nil {
-			l.Crit("Couldn't parse gid %q: %v", g.Gid, err)
-			return nil
-		}
-		tmp := int(gid)
-		f.Group.ID = &tmp
+
+	decompressed, err := decompressFileStream(l, contents, reader)
+	if err != nil {
+		l.Crit("Error decompressing file %q: %v", path, err)
+		reader.Close()
+		return nil
 	}
 
 	return &File{
-		Path:        f.Path,
-		ReadCloser:  reader,
-		Hash:        fileHash,
-		Mode:        os.FileMode(f.Mode),
-		Uid:         *f.User.ID,
-		Gid:         *f.Group.ID,
-		expectedSum: expectedSum,
+		ReadCloser: decompressed,
+		hashes:     hashes,
+		signed:     signed,
+		signature:  signature,
+		sigHash:    sigHash,
+		keys:       keys,
 	}
 }
 
-// gzipReader is a wrapper for gzip's reader that closes the stream it wraps as well
-// as itself when Close() is called.
-type gzipReader struct {
-	*gzip.Reader //actually a ReadCloser
-	source       io.Closer
-}
+// WriteLink creates the link described by s. If the link already exists
+// nothing is done. Any other node at the path is replaced if s allows it, and
+// otherwise ErrNodeExists is returned. The targets of hard links are resolved
+// within DestDir (see ResolveParents). Symbolic links are owned by the user
+// and group IDs of s (see ResolveNodeOwner), whereas hard links share the
+// ownership of their target.
+func (u Util) WriteLink(s types.Link) error {
+	path := u.JoinPath(s.Path)
 
-func newGzipReader(reader io.ReadCloser) (io.ReadCloser, error) {
-	gzReader, err := gzip.NewReader(reader)
-	if err != nil {
-		return nil, err
+	if err := MkdirForFile(path); err != nil {
+		return err
 	}
-	return gzipReader{
-		Reader: gzReader,
-		source: reader,
-	}, nil
-}
 
-func (gz gzipReader) Close() error {
-	if err := gz.Reader.Close(); err != nil {
-		return err
+	var target string
+	if s.Hard {
+		var err error
+		if target, err = u.ResolveParents(s.Target); err != nil {
+			return err
+		}
 	}
-	if err := gz.source.Close(); err != nil {
+
+	if info, err := os.Lstat(path); err == nil {
+		if sameLink(s, target, path, info) {
+			return nil
+		}
+		if !s.ShouldOverwrite() {
+			return ErrNodeExists
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
 		return err
 	}
-	return nil
-}
 
-func decompressFileStream(l *log.Logger, f types.File, contents io.ReadCloser) (io.ReadCloser, error) {
-	switch f.Contents.Compression {
-	case "":
-		return contents, nil
-	case "gzip":
-		return newGzipReader(contents)
-	default:
-		return nil, types.ErrCompressionInvalid
+	if s.Hard {
+		return os.Link(target, path)
 	}
-}
-
-func (u Util) WriteLink(s types.Link) error {
-	path := u.JoinPath(s.Path)
 
-	if err := MkdirForFile(path); err != nil {
+	if err := os.Symlink(s.Target, path); err != nil {
 		return err
 	}
+	return os.Lchown(path, idOrRoot(s.User.ID), idOrRoot(s.Group.ID))
+}
 
+// sameLink returns whether the node at path, described by info, is the link
+// s. target is the resolved target of hard links.
+func sameLink(s types.Link, target, path string, info os.FileInfo) bool {
 	if s.Hard {
-		return os.Link(s.Target, path)
+		targetInfo, err := os.Lstat(target)
+		return err == nil && os.SameFile(info, targetInfo)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return false
 	}
-	return os.Symlink(s.Target, path)
+	target, err := os.Readlink(path)
+	return err == nil && target == s.Target
 }
 
 // WriteFile creates and writes the file described by f using the provided context.
+// If f may not overwrite a different node at its path, the existing node is left
+// in place if it is a file with the same contents, and ErrNodeExists is returned
+// otherwise. Appending to the existing file is not considered overwriting it.
 func (u Util) WriteFile(f *File) error {
 	defer f.Close()
 	var err error
@@ -231,15 +273,42 @@ func (u Util) WriteFile(f *File) error {
 
 	fileWriter := bufio.NewWriter(tmp)
 
+	if f.appendExisting {
+		if err = copyExisting(fileWriter, path); err != nil {
+			return err
+		}
+	}
+
 	if _, err = io.Copy(fileWriter, f); err != nil {
 		return err
 	}
-	fileWriter.Flush()
-
 	if err = f.Verify(); err != nil {
 		return err
 	}
 
+	for _, a := range f.appends {
+		if _, err = io.Copy(fileWriter, a); err != nil {
+			return err
+		}
+		if err = a.Verify(); err != nil {
+			return err
+		}
+	}
+	if err = fileWriter.Flush(); err != nil {
+		return err
+	}
+
+	if !f.overwrite && !f.appendExisting {
+		var same bool
+		if same, err = sameContents(path, tmp.Name()); err != nil {
+			return err
+		}
+		if same {
+			os.Remove(tmp.Name())
+			return nil
+		}
+	}
+
 	// XXX(vc): Note that we assume to be operating on the file we just wrote, this is only guaranteed
 	// by using syscall.Fchown() and syscall.Fchmod()
 
@@ -259,6 +328,73 @@ func (u Util) WriteFile(f *File) error {
 	return nil
 }
 
+// sameContents returns whether the regular file at path has the same contents
+// as the file at tmp. If there is no node at path, it returns false. If there
+// is a different node, it returns ErrNodeExists.
+func sameContents(path, tmp string) (bool, error) {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, ErrNodeExists
+	}
+	tmpInfo, err := os.Stat(tmp)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != tmpInfo.Size() {
+		return false, ErrNodeExists
+	}
+
+	existing, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer existing.Close()
+	written, err := os.Open(tmp)
+	if err != nil {
+		return false, err
+	}
+	defer written.Close()
+
+	a := make([]byte, 32*1024)
+	b := make([]byte, 32*1024)
+	for {
+		n, errA := io.ReadFull(existing, a)
+		m, errB := io.ReadFull(written, b)
+		if !bytes.Equal(a[:n], b[:m]) {
+			return false, ErrNodeExists
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			// The sizes match, so both are at their end.
+			return true, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
+
+// copyExisting copies the contents of the file at path, if it exists, to w.
+func copyExisting(w io.Writer, path string) error {
+	existing, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer existing.Close()
+
+	_, err = io.Copy(w, existing)
+	return err
+}
+
 // MkdirForFile helper creates the directory components of path.
 func MkdirForFile(path string) error {
 	return os.MkdirAll(filepath.Dir(path), DefaultDirectoryPermissions)
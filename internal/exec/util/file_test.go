@@ -0,0 +1,386 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+)
+
+func TestWriteFileAppend(t *testing.T) {
+	type in struct {
+		existing *string
+		contents string
+		append   []types.FileContents
+	}
+	type out struct {
+		data string
+		err  bool
+	}
+
+	existing := "old\n"
+	badHash := "sha512-" + strings.Repeat("0", 128)
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{existing: &existing, contents: "data:,a%0A"},
+			out: out{data: "a\n"},
+		},
+		{
+			in: in{
+				existing: &existing,
+				contents: "data:,a%0A",
+				append:   []types.FileContents{{Source: "data:,b%0A"}, {Source: "data:,c%0A"}},
+			},
+			out: out{data: "a\nb\nc\n"},
+		},
+		{
+			in: in{
+				existing: &existing,
+				append:   []types.FileContents{{Source: "data:,b%0A"}},
+			},
+			out: out{data: "old\nb\n"},
+		},
+		{
+			in: in{
+				append: []types.FileContents{{Source: "data:,b%0A"}},
+			},
+			out: out{data: "b\n"},
+		},
+		{
+			in: in{
+				existing: &existing,
+				append: []types.FileContents{{
+					Source:       "data:,b%0A",
+					Verification: types.Verification{Hash: &badHash},
+				}},
+			},
+			out: out{data: "old\n", err: true},
+		},
+	}
+
+	logger := log.New()
+	defer logger.Close()
+	client := resource.NewHttpClient(&logger, types.Timeouts{})
+
+	for i, test := range tests {
+		func() {
+			dir, err := ioutil.TempDir("", "ignition-append")
+			if err != nil {
+				t.Fatalf("#%d: failed to create temp dir: %v", i, err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "etc/hosts")
+			if test.in.existing != nil {
+				if err := MkdirForFile(path); err != nil {
+					t.Fatalf("#%d: failed to create directory: %v", i, err)
+				}
+				if err := ioutil.WriteFile(path, []byte(*test.in.existing), 0644); err != nil {
+					t.Fatalf("#%d: failed to write existing file: %v", i, err)
+				}
+			}
+
+			f := types.File{
+				Node: types.Node{
+					Path:  "/etc/hosts",
+					User:  types.NodeUser{ID: func(i int) *int { return &i }(os.Getuid())},
+					Group: types.NodeGroup{ID: func(i int) *int { return &i }(os.Getgid())},
+				},
+				FileEmbedded1: types.FileEmbedded1{
+					Contents: types.FileContents{Source: test.in.contents},
+					Append:   test.in.append,
+					Mode:     0644,
+				},
+			}
+			file := RenderFile(&logger, &client, f, nil)
+			if file == nil {
+				t.Fatalf("#%d: failed to render file", i)
+			}
+
+			err = Util{DestDir: dir, Logger: &logger}.WriteFile(file)
+			if test.out.err != (err != nil) {
+				t.Errorf("#%d: bad err: want %t, got %v", i, test.out.err, err)
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("#%d: failed to read file: %v", i, err)
+			}
+			if string(data) != test.out.data {
+				t.Errorf("#%d: bad data: want %q, got %q", i, test.out.data, data)
+			}
+		}()
+	}
+}
+
+func TestWriteFileOverwrite(t *testing.T) {
+	type in struct {
+		existing  *string
+		overwrite *bool
+	}
+	type out struct {
+		data string
+		err  error
+	}
+
+	same := "a\n"
+	different := "old\n"
+	yes, no := true, false
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{overwrite: &no},
+			out: out{data: "a\n"},
+		},
+		{
+			in:  in{existing: &same, overwrite: &no},
+			out: out{data: "a\n"},
+		},
+		{
+			in:  in{existing: &different, overwrite: &no},
+			out: out{data: "old\n", err: ErrNodeExists},
+		},
+		{
+			in:  in{existing: &different, overwrite: &yes},
+			out: out{data: "a\n"},
+		},
+		{
+			in:  in{existing: &different},
+			out: out{data: "a\n"},
+		},
+	}
+
+	logger := log.New()
+	defer logger.Close()
+	client := resource.NewHttpClient(&logger, types.Timeouts{})
+
+	for i, test := range tests {
+		func() {
+			dir, err := ioutil.TempDir("", "ignition-overwrite")
+			if err != nil {
+				t.Fatalf("#%d: failed to create temp dir: %v", i, err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "file")
+			if test.in.existing != nil {
+				if err := ioutil.WriteFile(path, []byte(*test.in.existing), 0644); err != nil {
+					t.Fatalf("#%d: failed to write existing file: %v", i, err)
+				}
+			}
+
+			f := types.File{
+				Node: types.Node{
+					Path:      "/file",
+					User:      types.NodeUser{ID: func(i int) *int { return &i }(os.Getuid())},
+					Group:     types.NodeGroup{ID: func(i int) *int { return &i }(os.Getgid())},
+					Overwrite: test.in.overwrite,
+				},
+				FileEmbedded1: types.FileEmbedded1{
+					Contents: types.FileContents{Source: "data:,a%0A"},
+					Mode:     0644,
+				},
+			}
+			file := RenderFile(&logger, &client, f, nil)
+			if file == nil {
+				t.Fatalf("#%d: failed to render file", i)
+			}
+
+			err = Util{DestDir: dir, Logger: &logger}.WriteFile(file)
+			if err != test.out.err {
+				t.Errorf("#%d: bad err: want %v, got %v", i, test.out.err, err)
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("#%d: failed to read file: %v", i, err)
+			}
+			if string(data) != test.out.data {
+				t.Errorf("#%d: bad data: want %q, got %q", i, test.out.data, data)
+			}
+			if names, _ := filepath.Glob(filepath.Join(dir, "tmp*")); len(names) != 0 {
+				t.Errorf("#%d: temporary files left behind: %v", i, names)
+			}
+		}()
+	}
+}
+
+func TestWriteLinkOverwrite(t *testing.T) {
+	type in struct {
+		existing  string // target of an existing symlink, if any
+		overwrite *bool
+	}
+	type out struct {
+		target string
+		err    error
+	}
+
+	yes := true
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{},
+			out: out{target: "/etc/a"},
+		},
+		{
+			in:  in{existing: "/etc/a"},
+			out: out{target: "/etc/a"},
+		},
+		{
+			in:  in{existing: "/etc/b"},
+			out: out{target: "/etc/b", err: ErrNodeExists},
+		},
+		{
+			in:  in{existing: "/etc/b", overwrite: &yes},
+			out: out{target: "/etc/a"},
+		},
+	}
+
+	for i, test := range tests {
+		func() {
+			dir, err := ioutil.TempDir("", "ignition-overwrite")
+			if err != nil {
+				t.Fatalf("#%d: failed to create temp dir: %v", i, err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "link")
+			if test.in.existing != "" {
+				if err := os.Symlink(test.in.existing, path); err != nil {
+					t.Fatalf("#%d: failed to create existing link: %v", i, err)
+				}
+			}
+
+			err = Util{DestDir: dir}.WriteLink(types.Link{
+				Node:          types.Node{Path: "/link", Overwrite: test.in.overwrite},
+				LinkEmbedded1: types.LinkEmbedded1{Target: "/etc/a"},
+			})
+			if err != test.out.err {
+				t.Errorf("#%d: bad err: want %v, got %v", i, test.out.err, err)
+			}
+			target, err := os.Readlink(path)
+			if err != nil {
+				t.Fatalf("#%d: failed to read link: %v", i, err)
+			}
+			if target != test.out.target {
+				t.Errorf("#%d: bad target: want %q, got %q", i, test.out.target, target)
+			}
+		}()
+	}
+}
+
+func TestWriteLink(t *testing.T) {
+	type in struct {
+		link types.Link
+	}
+	type out struct {
+		err error
+	}
+
+	uid, gid := os.Getuid(), os.Getgid()
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in: in{link: types.Link{
+				Node:          types.Node{Path: "/etc/b", User: types.NodeUser{ID: &uid}, Group: types.NodeGroup{ID: &gid}},
+				LinkEmbedded1: types.LinkEmbedded1{Target: "/etc/a"},
+			}},
+		},
+		{
+			in: in{link: types.Link{
+				Node:          types.Node{Path: "/etc/b"},
+				LinkEmbedded1: types.LinkEmbedded1{Target: "/etc/a", Hard: true},
+			}},
+		},
+		{
+			in: in{link: types.Link{
+				Node:          types.Node{Path: "/etc/b"},
+				LinkEmbedded1: types.LinkEmbedded1{Target: "/escape/victim", Hard: true},
+			}},
+			out: out{err: ErrPathOutsideDestDir},
+		},
+	}
+
+	for i, test := range tests {
+		func() {
+			dir, err := ioutil.TempDir("", "ignition-link")
+			if err != nil {
+				t.Fatalf("#%d: failed to create temp dir: %v", i, err)
+			}
+			defer os.RemoveAll(dir)
+
+			root := filepath.Join(dir, "root")
+			if err := os.MkdirAll(filepath.Join(root, "etc"), 0755); err != nil {
+				t.Fatalf("#%d: failed to create directory: %v", i, err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(root, "etc/a"), nil, 0644); err != nil {
+				t.Fatalf("#%d: failed to create file: %v", i, err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, "victim"), nil, 0644); err != nil {
+				t.Fatalf("#%d: failed to create file: %v", i, err)
+			}
+			if err := os.Symlink(dir, filepath.Join(root, "escape")); err != nil {
+				t.Fatalf("#%d: failed to create link: %v", i, err)
+			}
+
+			s := test.in.link
+			err = Util{DestDir: root}.WriteLink(s)
+			if err != test.out.err {
+				t.Errorf("#%d: bad err: want %v, got %v", i, test.out.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			info, err := os.Lstat(filepath.Join(root, s.Path))
+			if err != nil {
+				t.Fatalf("#%d: failed to stat link: %v", i, err)
+			}
+			if s.Hard {
+				target, err := os.Stat(filepath.Join(root, s.Target))
+				if err != nil {
+					t.Fatalf("#%d: failed to stat target: %v", i, err)
+				}
+				if !os.SameFile(info, target) {
+					t.Errorf("#%d: hard link doesn't refer to the target in the filesystem", i)
+				}
+				return
+			}
+			stat := info.Sys().(*syscall.Stat_t)
+			if int(stat.Uid) != *s.User.ID || int(stat.Gid) != *s.Group.ID {
+				t.Errorf("#%d: bad owner: want %d:%d, got %d:%d", i, *s.User.ID, *s.Group.ID, stat.Uid, stat.Gid)
+			}
+		}()
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	losetupCmd = "/sbin/losetup"
+	udevadmCmd = "/bin/udevadm"
+
+	devicePollInterval = 100 * time.Millisecond
+	devicePollTimeout  = 90 * time.Second
+)
+
+// WaitOnDevicesFunc waits for the devices named in devs to be available,
+// using ctxt to identify the caller.
+type WaitOnDevicesFunc func(devs []string, ctxt string) error
+
+// AttachLoopDevice attaches the image file at path to the next free loop
+// device, with partition scanning enabled so that the kernel creates devices
+// for the partitions of the image. The path of the loop device is returned.
+func AttachLoopDevice(path string) (string, error) {
+	out, err := exec.Command(losetupCmd, "--find", "--show", "--partscan", path).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("losetup failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// DetachLoopDevice detaches the loop device dev from its image file.
+func DetachLoopDevice(dev string) error {
+	if out, err := exec.Command(losetupCmd, "--detach", dev).CombinedOutput(); err != nil {
+		return fmt.Errorf("losetup failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// MapDevicePath maps path onto the loop device loop if it names the device dev
+// or one of its partitions (e.g. /dev/sda9 or /dev/nvme0n1p9). Partitions are
+// mapped using the loop device's naming (e.g. /dev/loop0p9). Any other path
+// is returned as is.
+func MapDevicePath(path, dev, loop string) string {
+	if path == dev {
+		return loop
+	}
+	if !strings.HasPrefix(path, dev) {
+		return path
+	}
+	part := strings.TrimPrefix(strings.TrimPrefix(path, dev), "p")
+	if part == "" || strings.Trim(part, "0123456789") != "" {
+		return path
+	}
+	return loop + "p" + part
+}
+
+// PollDevices waits for the devices named in devs to appear as block devices.
+// It is an alternative to systemd.WaitOnDevices for systems where Ignition
+// isn't run by systemd: udev is asked to finish processing its events, and
+// then the device nodes and their entries in sysfs are polled for.
+func PollDevices(devs []string, ctxt string) error {
+	if out, err := exec.Command(udevadmCmd, "settle").CombinedOutput(); err != nil {
+		return fmt.Errorf("udevadm settle failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	deadline := time.Now().Add(devicePollTimeout)
+	for _, dev := range devs {
+		for !blockDeviceExists(dev) {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timed out waiting for %s device %s", ctxt, dev)
+			}
+			time.Sleep(devicePollInterval)
+		}
+	}
+
+	return nil
+}
+
+// blockDeviceExists returns whether dev resolves to a device node of a block
+// device which the kernel lists in sysfs.
+func blockDeviceExists(dev string) bool {
+	path, err := filepath.EvalSymlinks(dev)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeDevice == 0 || info.Mode()&os.ModeCharDevice != 0 {
+		return false
+	}
+	_, err = os.Stat(filepath.Join("/sys/class/block", filepath.Base(path)))
+	return err == nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+)
+
+func TestMapDevicePath(t *testing.T) {
+	type in struct {
+		path string
+		dev  string
+	}
+	type out struct {
+		path string
+	}
+
+	loop := "/dev/loop0"
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{path: "/dev/sda", dev: "/dev/sda"},
+			out: out{path: "/dev/loop0"},
+		},
+		{
+			in:  in{path: "/dev/sda9", dev: "/dev/sda"},
+			out: out{path: "/dev/loop0p9"},
+		},
+		{
+			in:  in{path: "/dev/nvme0n1p12", dev: "/dev/nvme0n1"},
+			out: out{path: "/dev/loop0p12"},
+		},
+		{
+			in:  in{path: "/dev/sdb1", dev: "/dev/sda"},
+			out: out{path: "/dev/sdb1"},
+		},
+		{
+			in:  in{path: "/dev/sdaa", dev: "/dev/sda"},
+			out: out{path: "/dev/sdaa"},
+		},
+		{
+			in:  in{path: "/dev/sdap", dev: "/dev/sda"},
+			out: out{path: "/dev/sdap"},
+		},
+		{
+			in:  in{path: "/dev/disk/by-partlabel/ROOT", dev: "/dev/sda"},
+			out: out{path: "/dev/disk/by-partlabel/ROOT"},
+		},
+	}
+
+	for i, test := range tests {
+		path := MapDevicePath(test.in.path, test.in.dev, loop)
+		if path != test.out.path {
+			t.Errorf("#%d: bad path: want %q, got %q", i, test.out.path, path)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+const (
+	mountCmd = "/bin/mount"
+)
+
+// MountedFilesystems returns the filesystems of config which have a mount
+// path, sorted so that every filesystem comes after those mounted above it.
+func MountedFilesystems(config types.Config) []types.Filesystem {
+	fss := []types.Filesystem{}
+	for _, fs := range config.Storage.Filesystems {
+		if fs.Mount != nil && fs.Mount.Path != nil {
+			fss = append(fss, fs)
+		}
+	}
+	sort.Stable(byMountDepth(fss))
+	return fss
+}
+
+type byMountDepth []types.Filesystem
+
+func (lst byMountDepth) Len() int      { return len(lst) }
+func (lst byMountDepth) Swap(i, j int) { lst[i], lst[j] = lst[j], lst[i] }
+func (lst byMountDepth) Less(i, j int) bool {
+	return mountDepth(*lst[i].Mount.Path) < mountDepth(*lst[j].Mount.Path)
+}
+
+func mountDepth(path string) int {
+	path = filepath.Clean(path)
+	if path == "/" {
+		return 0
+	}
+	return strings.Count(path, "/")
+}
+
+// MountPath returns the path into the context of the mount path of m, with the
+// links among its parent directories resolved (see ResolveParents).
+func (u Util) MountPath(m types.Mount) (string, error) {
+	return u.ResolveParents(*m.Path)
+}
+
+// IsMounted returns whether a filesystem is mounted on the mount path of m.
+func (u Util) IsMounted(m types.Mount) (bool, error) {
+	path, err := u.MountPath(m)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	mnts, err := mountPoints()
+	if err != nil {
+		return false, err
+	}
+	for _, mnt := range mnts {
+		if mnt == path {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MountFilesystem mounts the filesystem described by m on its mount path,
+// creating the directory if needed, with the mount options of m.
+func (u Util) MountFilesystem(m types.Mount) error {
+	if err := os.MkdirAll(u.JoinPath(*m.Path), 0755); err != nil {
+		return err
+	}
+	path, err := u.MountPath(m)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-t", m.Format}
+	if len(m.MountOptions) > 0 {
+		opts := make([]string, 0, len(m.MountOptions))
+		for _, o := range m.MountOptions {
+			opts = append(opts, string(o))
+		}
+		args = append(args, "-o", strings.Join(opts, ","))
+	}
+	args = append(args, m.Device, path)
+
+	if out, err := exec.Command(mountCmd, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("mount failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// UnmountFilesystem unmounts the filesystem mounted on the mount path of m.
+func (u Util) UnmountFilesystem(m types.Mount) error {
+	path, err := u.MountPath(m)
+	if err != nil {
+		return err
+	}
+	return syscall.Unmount(path, 0)
+}
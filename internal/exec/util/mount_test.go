@@ -0,0 +1,67 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestMountedFilesystems(t *testing.T) {
+	type in struct {
+		config types.Config
+	}
+	type out struct {
+		names []string
+	}
+
+	root := "/sysroot"
+	mount := func(path string) *types.Mount {
+		return &types.Mount{Device: "/dev/sda1", Format: "xfs", Path: &path}
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{config: types.Config{}},
+			out: out{names: []string{}},
+		},
+		{
+			in: in{config: types.Config{Storage: types.Storage{Filesystems: []types.Filesystem{
+				{Name: "root", Path: &root},
+				{Name: "data", Mount: &types.Mount{Device: "/dev/sdb1", Format: "ext4"}},
+				{Name: "log", Mount: mount("/var/log/")},
+				{Name: "var", Mount: mount("/var")},
+				{Name: "srv", Mount: mount("/srv")},
+				{Name: "slash", Mount: mount("/")},
+			}}}},
+			out: out{names: []string{"slash", "var", "srv", "log"}},
+		},
+	}
+
+	for i, test := range tests {
+		names := []string{}
+		for _, fs := range MountedFilesystems(test.in.config) {
+			names = append(names, fs.Name)
+		}
+		if !reflect.DeepEqual(test.out.names, names) {
+			t.Errorf("#%d: bad filesystems: want %v, got %v", i, test.out.names, names)
+		}
+	}
+}
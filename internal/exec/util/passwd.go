@@ -131,8 +131,12 @@ func translateV2_1UsercreateGroupSliceToPasswdUserGroupSlice(groups []types.User
 }
 
 func (u Util) CheckIfUserExists(c types.PasswdUser) (bool, error) {
-	code, err := u.LogCmd(exec.Command("chroot", u.DestDir, "id", c.Name),
-		"checking if user %q exists", c.Name)
+	var code int
+	err := u.Inspect(func() (err error) {
+		code, err = u.LogCmd(exec.Command("chroot", u.DestDir, "id", c.Name),
+			"checking if user %q exists", c.Name)
+		return
+	})
 	if err != nil {
 		if code == 1 {
 			return false, nil
@@ -250,3 +254,50 @@ func (u Util) CreateGroup(g types.PasswdGroup) error {
 		"adding group %q", g.Name)
 	return err
 }
+
+// ResolveNodeOwner returns n with the names of its user and group replaced by
+// their IDs, as looked up in the passwd and group files of DestDir. Users and
+// groups which are unset are root.
+func (u Util) ResolveNodeOwner(n types.Node) (types.Node, error) {
+	if n.User.Name != "" {
+		usr, err := u.userLookup(n.User.Name)
+		if err != nil {
+			return types.Node{}, fmt.Errorf("no such user %q: %v", n.User.Name, err)
+		}
+		uid, err := strconv.Atoi(usr.Uid)
+		if err != nil {
+			return types.Node{}, fmt.Errorf("couldn't parse uid %q: %v", usr.Uid, err)
+		}
+		n.User = types.NodeUser{ID: &uid}
+	} else if n.User.ID == nil {
+		n.User.ID = intToPtr(0)
+	}
+
+	if n.Group.Name != "" {
+		grp, err := u.groupLookup(n.Group.Name)
+		if err != nil {
+			return types.Node{}, fmt.Errorf("no such group %q: %v", n.Group.Name, err)
+		}
+		gid, err := strconv.Atoi(grp.Gid)
+		if err != nil {
+			return types.Node{}, fmt.Errorf("couldn't parse gid %q: %v", grp.Gid, err)
+		}
+		n.Group = types.NodeGroup{ID: &gid}
+	} else if n.Group.ID == nil {
+		n.Group.ID = intToPtr(0)
+	}
+
+	return n, nil
+}
+
+// idOrRoot returns the ID id points to, or that of root if it is nil.
+func idOrRoot(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+func intToPtr(x int) *int {
+	return &x
+}
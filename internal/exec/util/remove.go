@@ -0,0 +1,158 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+const (
+	mountInfoPath = "/proc/self/mountinfo"
+)
+
+var (
+	ErrRemoveNotRecursive = errors.New("directory is not empty and recursive removal was not requested")
+	ErrRemoveMountPoint   = errors.New("directory is or contains a mount point")
+)
+
+// RemoveNode removes the node described by rm. Nothing is done if it doesn't
+// exist. Links are removed rather than followed (see ResolveParents).
+// Non-empty directories are only removed if rm.Recursive is set, and never if
+// a filesystem is mounted on or below them.
+func (u Util) RemoveNode(rm types.Removal) error {
+	if filepath.Clean(rm.Path) == "/" {
+		return types.ErrRemoveRoot
+	}
+	path, err := u.ResolveParents(rm.Path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return os.Remove(path)
+	}
+
+	mounted, err := containsMountPoint(path)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		return ErrRemoveMountPoint
+	}
+
+	if rm.Recursive {
+		return os.RemoveAll(path)
+	}
+	empty, err := isEmptyDir(path)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return ErrRemoveNotRecursive
+	}
+	return os.Remove(path)
+}
+
+func isEmptyDir(path string) (bool, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer dir.Close()
+
+	if _, err := dir.Readdirnames(1); err == io.EOF {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// containsMountPoint returns whether a filesystem is mounted on path or any
+// path below it.
+func containsMountPoint(path string) (bool, error) {
+	mnts, err := mountPoints()
+	if err != nil {
+		return false, err
+	}
+	for _, mnt := range mnts {
+		if mnt == path || strings.HasPrefix(mnt, path+string(filepath.Separator)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mountPoints returns the paths which filesystems are mounted on.
+func mountPoints() ([]string, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mnts := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// The mount point is the fifth field, with spaces and the like
+		// escaped as octal.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("malformed line in %s: %q", mountInfoPath, scanner.Text())
+		}
+		mnt, err := unescapeMountPath(fields[4])
+		if err != nil {
+			return nil, err
+		}
+		mnts = append(mnts, mnt)
+	}
+	return mnts, scanner.Err()
+}
+
+func unescapeMountPath(s string) (string, error) {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			c, err := strconv.ParseUint(s[i+1:i+4], 8, 8)
+			if err != nil {
+				return "", err
+			}
+			b.WriteByte(byte(c))
+			i += 3
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String(), nil
+}
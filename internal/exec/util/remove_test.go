@@ -0,0 +1,150 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestRemoveNode(t *testing.T) {
+	type in struct {
+		removal types.Removal
+	}
+	type out struct {
+		err     error
+		removed []string
+		kept    []string
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{removal: types.Removal{Path: "/etc/motd.d/10-sample"}},
+			out: out{removed: []string{"/etc/motd.d/10-sample"}, kept: []string{"/etc/motd.d"}},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/etc/missing"}},
+			out: out{kept: []string{"/etc/motd.d/10-sample"}},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/missing/missing"}},
+			out: out{},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/etc/motd.d"}},
+			out: out{err: ErrRemoveNotRecursive, kept: []string{"/etc/motd.d/10-sample"}},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/etc/motd.d", Recursive: true}},
+			out: out{removed: []string{"/etc/motd.d"}, kept: []string{"/etc"}},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/etc/empty"}},
+			out: out{removed: []string{"/etc/empty"}},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/etc/link"}},
+			out: out{removed: []string{"/etc/link"}, kept: []string{"/etc/motd.d/10-sample"}},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/escape/victim"}},
+			out: out{err: ErrPathOutsideDestDir},
+		},
+		{
+			in:  in{removal: types.Removal{Path: "/", Recursive: true}},
+			out: out{err: types.ErrRemoveRoot, kept: []string{"/etc"}},
+		},
+	}
+
+	for i, test := range tests {
+		func() {
+			dir, err := ioutil.TempDir("", "ignition-remove")
+			if err != nil {
+				t.Fatalf("#%d: failed to create temp dir: %v", i, err)
+			}
+			defer os.RemoveAll(dir)
+			outside, err := ioutil.TempDir("", "ignition-remove-outside")
+			if err != nil {
+				t.Fatalf("#%d: failed to create temp dir: %v", i, err)
+			}
+			defer os.RemoveAll(outside)
+
+			root := filepath.Join(dir, "root")
+			for _, d := range []string{"etc/motd.d", "etc/empty"} {
+				if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+					t.Fatalf("#%d: failed to create directory: %v", i, err)
+				}
+			}
+			if err := ioutil.WriteFile(filepath.Join(root, "etc/motd.d/10-sample"), nil, 0644); err != nil {
+				t.Fatalf("#%d: failed to create file: %v", i, err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(outside, "victim"), nil, 0644); err != nil {
+				t.Fatalf("#%d: failed to create file: %v", i, err)
+			}
+			if err := os.Symlink("motd.d", filepath.Join(root, "etc/link")); err != nil {
+				t.Fatalf("#%d: failed to create link: %v", i, err)
+			}
+			if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+				t.Fatalf("#%d: failed to create link: %v", i, err)
+			}
+
+			err = Util{DestDir: root}.RemoveNode(test.in.removal)
+			if err != test.out.err {
+				t.Errorf("#%d: bad err: want %v, got %v", i, test.out.err, err)
+			}
+			for _, p := range test.out.removed {
+				if _, err := os.Lstat(filepath.Join(root, p)); !os.IsNotExist(err) {
+					t.Errorf("#%d: %q wasn't removed", i, p)
+				}
+			}
+			for _, p := range test.out.kept {
+				if _, err := os.Lstat(filepath.Join(root, p)); err != nil {
+					t.Errorf("#%d: %q was removed", i, p)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(outside, "victim")); err != nil {
+				t.Errorf("#%d: file outside of the filesystem was removed", i)
+			}
+		}()
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{in: "/sysroot", out: "/sysroot"},
+		{in: `/mnt/with\040space`, out: "/mnt/with space"},
+		{in: `/mnt/tab\011and\134backslash`, out: "/mnt/tab\tand\\backslash"},
+	}
+
+	for i, test := range tests {
+		out, err := unescapeMountPath(test.in)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		}
+		if out != test.out {
+			t.Errorf("#%d: bad path: want %q, got %q", i, test.out, out)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/url"
+	"text/template"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+
+	"github.com/vincent-petithory/dataurl"
+	"golang.org/x/net/context"
+)
+
+// RenderTemplate executes text as a Go template against the provided
+// metadata. Referencing a variable that isn't present in the metadata is an
+// error.
+func RenderTemplate(name, text string, metadata map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, metadata); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderFileTemplate renders the contents of the file and each of the
+// fragments to append which are marked as templates (see
+// renderContentsTemplate). Contents which aren't templates are left as is.
+func RenderFileTemplate(l *log.Logger, c *resource.HttpClient, f types.File, metadata map[string]string, keys Keyring) (types.File, error) {
+	var err error
+	if f.Contents.Template {
+		if f.Contents, err = renderContentsTemplate(l, c, f.Path, f.Contents, metadata, keys); err != nil {
+			return types.File{}, err
+		}
+	}
+
+	appends := make([]types.FileContents, len(f.Append))
+	for i, contents := range f.Append {
+		if contents.Template {
+			if contents, err = renderContentsTemplate(l, c, f.Path, contents, metadata, keys); err != nil {
+				return types.File{}, err
+			}
+		}
+		appends[i] = contents
+	}
+	if f.Append != nil {
+		f.Append = appends
+	}
+	return f, nil
+}
+
+// renderContentsTemplate substitutes the metadata into the source URL of the
+// contents, then fetches, verifies and decompresses them and renders them as
+// a template. The result is returned inline as a data URL so that the files
+// stage writes it like any other file.
+func renderContentsTemplate(l *log.Logger, c *resource.HttpClient, path string, fc types.FileContents, metadata map[string]string, keys Keyring) (types.FileContents, error) {
+	source, err := RenderTemplate(path, fc.Source, metadata)
+	if err != nil {
+		return types.FileContents{}, err
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return types.FileContents{}, err
+	}
+
+	data, err := resource.Fetch(l, c, context.Background(), *u)
+	if err != nil {
+		return types.FileContents{}, err
+	}
+	sig, err := FetchSignature(l, c, fc.Verification, source)
+	if err != nil {
+		return types.FileContents{}, err
+	}
+	if err := AssertValid(fc.Verification, data, sig, keys); err != nil {
+		return types.FileContents{}, err
+	}
+
+	reader, err := decompressFileStream(l, fc, ioutil.NopCloser(bytes.NewReader(data)))
+	if err != nil {
+		return types.FileContents{}, err
+	}
+	defer reader.Close()
+	data, err = ioutil.ReadAll(reader)
+	if err != nil {
+		return types.FileContents{}, err
+	}
+
+	contents, err := RenderTemplate(path, string(data), metadata)
+	if err != nil {
+		return types.FileContents{}, err
+	}
+
+	return types.FileContents{
+		Source: dataurl.EncodeBytes([]byte(contents)),
+	}, nil
+}
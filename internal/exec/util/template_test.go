@@ -0,0 +1,162 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coreos/ignition/config/types"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	type in struct {
+		text     string
+		metadata map[string]string
+	}
+	type out struct {
+		text string
+		err  bool
+	}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in:  in{text: "no variables"},
+			out: out{text: "no variables"},
+		},
+		{
+			in: in{
+				text:     "ADDRESS={{.local_ipv4}}\nHOST={{.hostname}}\n",
+				metadata: map[string]string{"local_ipv4": "10.0.0.2", "hostname": "node1"},
+			},
+			out: out{text: "ADDRESS=10.0.0.2\nHOST=node1\n"},
+		},
+		{
+			in: in{
+				text:     "{{.public_ipv4}}",
+				metadata: map[string]string{"local_ipv4": "10.0.0.2"},
+			},
+			out: out{err: true},
+		},
+		{
+			in:  in{text: "{{.unterminated"},
+			out: out{err: true},
+		},
+	}
+
+	for i, test := range tests {
+		text, err := RenderTemplate("test", test.in.text, test.in.metadata)
+		if test.out.err != (err != nil) {
+			t.Errorf("#%d: bad err: want %t, got %v", i, test.out.err, err)
+		}
+		if text != test.out.text {
+			t.Errorf("#%d: bad text: want %q, got %q", i, test.out.text, text)
+		}
+	}
+}
+
+func TestRenderFileTemplate(t *testing.T) {
+	type in struct {
+		file     types.File
+		metadata map[string]string
+	}
+	type out struct {
+		file types.File
+		err  bool
+	}
+
+	stringDeref := func(s string) *string { return &s }
+	metadata := map[string]string{"instance_id": "i-0123", "local_ipv4": "10.0.0.2"}
+
+	tests := []struct {
+		in  in
+		out out
+	}{
+		{
+			in: in{
+				file: types.File{
+					Node: types.Node{Filesystem: "root", Path: "/etc/instance"},
+					FileEmbedded1: types.FileEmbedded1{Contents: types.FileContents{
+						Source:   "data:,id%3D%7B%7B.instance_id%7D%7D",
+						Template: true,
+					}},
+				},
+				metadata: metadata,
+			},
+			out: out{file: types.File{
+				Node:          types.Node{Filesystem: "root", Path: "/etc/instance"},
+				FileEmbedded1: types.FileEmbedded1{Contents: types.FileContents{Source: "data:text/plain;charset=utf-8;base64,aWQ9aS0wMTIz"}},
+			}},
+		},
+		{
+			in: in{
+				file: types.File{
+					Node: types.Node{Filesystem: "root", Path: "/etc/address"},
+					FileEmbedded1: types.FileEmbedded1{Contents: types.FileContents{
+						Source:   "data:,{{.local_ipv4}}",
+						Template: true,
+					}},
+				},
+				metadata: metadata,
+			},
+			out: out{file: types.File{
+				Node:          types.Node{Filesystem: "root", Path: "/etc/address"},
+				FileEmbedded1: types.FileEmbedded1{Contents: types.FileContents{Source: "data:text/plain;charset=utf-8;base64,MTAuMC4wLjI="}},
+			}},
+		},
+		{
+			in: in{
+				file: types.File{
+					Node: types.Node{Filesystem: "root", Path: "/etc/address"},
+					FileEmbedded1: types.FileEmbedded1{Contents: types.FileContents{
+						Source:   "data:,%7B%7B.public_ipv4%7D%7D",
+						Template: true,
+					}},
+				},
+				metadata: metadata,
+			},
+			out: out{err: true},
+		},
+		{
+			in: in{
+				file: types.File{
+					Node: types.Node{Filesystem: "root", Path: "/etc/instance"},
+					FileEmbedded1: types.FileEmbedded1{Contents: types.FileContents{
+						Source:   "data:,%7B%7B.instance_id%7D%7D",
+						Template: true,
+						Verification: types.Verification{
+							Hash: stringDeref("sha512-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+						},
+					}},
+				},
+				metadata: metadata,
+			},
+			out: out{err: true},
+		},
+	}
+
+	for i, test := range tests {
+		file, err := RenderFileTemplate(nil, nil, test.in.file, test.in.metadata, nil)
+		if test.out.err != (err != nil) {
+			t.Errorf("#%d: bad err: want %t, got %v", i, test.out.err, err)
+		}
+		if !reflect.DeepEqual(test.out.file, file) {
+			t.Errorf("#%d: bad file: want %+v, got %+v", i, test.out.file, file)
+		}
+	}
+}
@@ -19,8 +19,10 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/coreos/ignition/config/types"
 	"github.com/coreos/ignition/internal/log"
 )
 
@@ -129,3 +131,57 @@ func TestGroupLookup(t *testing.T) {
 		t.Fatalf("unexpected gid: %q", grp.Gid)
 	}
 }
+
+func TestResolveNodeOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("test requires root for chroot(), skipping")
+	}
+
+	td, err := tempBase()
+	if err != nil {
+		t.Fatalf("temp base error: %v", err)
+	}
+	defer os.RemoveAll(td)
+
+	logger := log.New()
+	defer logger.Close()
+
+	u := &Util{
+		DestDir: td,
+		Logger:  &logger,
+	}
+
+	id := func(i int) *int { return &i }
+	tests := []struct {
+		in  types.Node
+		out types.Node
+		err bool
+	}{
+		{
+			in:  types.Node{Path: "/a"},
+			out: types.Node{Path: "/a", User: types.NodeUser{ID: id(0)}, Group: types.NodeGroup{ID: id(0)}},
+		},
+		{
+			in:  types.Node{Path: "/a", User: types.NodeUser{ID: id(500)}, Group: types.NodeGroup{ID: id(501)}},
+			out: types.Node{Path: "/a", User: types.NodeUser{ID: id(500)}, Group: types.NodeGroup{ID: id(501)}},
+		},
+		{
+			in:  types.Node{Path: "/a", User: types.NodeUser{Name: "foo"}, Group: types.NodeGroup{Name: "foo"}},
+			out: types.Node{Path: "/a", User: types.NodeUser{ID: id(44)}, Group: types.NodeGroup{ID: id(4242)}},
+		},
+		{
+			in:  types.Node{Path: "/a", User: types.NodeUser{Name: "bar"}},
+			err: true,
+		},
+	}
+
+	for i, test := range tests {
+		node, err := u.ResolveNodeOwner(test.in)
+		if test.err != (err != nil) {
+			t.Errorf("#%d: bad err: want %t, got %v", i, test.err, err)
+		}
+		if !reflect.DeepEqual(test.out, node) {
+			t.Errorf("#%d: bad node: want %+v, got %+v", i, test.out, node)
+		}
+	}
+}
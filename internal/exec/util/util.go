@@ -15,11 +15,17 @@
 package util
 
 import (
+	"errors"
 	"path/filepath"
+	"strings"
 
 	"github.com/coreos/ignition/internal/log"
 )
 
+var (
+	ErrPathOutsideDestDir = errors.New("path resolves outside of the filesystem")
+)
+
 // Util encapsulates logging and destdir indirection for the util methods.
 type Util struct {
 	DestDir string // directory prefix to use in applying fs paths.
@@ -30,3 +36,25 @@ type Util struct {
 func (u Util) JoinPath(path ...string) string {
 	return filepath.Join(u.DestDir, filepath.Join(path...))
 }
+
+// ResolveParents returns the path into the context of p, with the links among
+// its parent directories resolved. The last component is left as is, so that
+// a link there can be operated on rather than followed. ErrPathOutsideDestDir
+// is returned if the path leads out of DestDir (e.g. through an absolute link,
+// which is only meaningful in the target system).
+func (u Util) ResolveParents(p string) (string, error) {
+	root, err := filepath.EvalSymlinks(u.DestDir)
+	if err != nil {
+		return "", err
+	}
+	path := u.JoinPath(p)
+
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	if parent != root && !strings.HasPrefix(parent, root+string(filepath.Separator)) {
+		return "", ErrPathOutsideDestDir
+	}
+	return filepath.Join(parent, filepath.Base(path)), nil
+}
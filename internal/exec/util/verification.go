@@ -15,67 +15,187 @@
 package util
 
 import (
-	"crypto/sha512"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // register sha384 and sha512 for crypto.Hash.New
+	"encoding/asn1"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
+	"math/big"
+	"net/url"
 
 	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrSignatureMissing = errors.New("signature required but not found")
+	ErrSignatureInvalid = errors.New("signature not made by any trusted key")
+	ErrNoTrustedKeys    = errors.New("signature required but no keys are trusted")
 )
 
 type ErrHashMismatch struct {
+	Function   string
 	Calculated string
 	Expected   string
 }
 
 func (e ErrHashMismatch) Error() string {
-	return fmt.Sprintf("hash verification failed (calculated %s but expected %s)",
-		e.Calculated, e.Expected)
+	return fmt.Sprintf("%s hash verification failed (calculated %s but expected %s)",
+		e.Function, e.Calculated, e.Expected)
 }
 
-func AssertValid(verify types.Verification, data []byte) error {
-	if hash := verify.Hash; hash != nil {
-		hashFunc, hashSum, err := verify.HashParts()
+// hashVerifier computes the sum of a resource with one of the requested hash
+// functions so it can be compared to the expected sum.
+type hashVerifier struct {
+	hash.Hash
+	function    string
+	expectedSum string
+}
+
+func (v hashVerifier) verify() error {
+	encodedSum := hex.EncodeToString(v.Sum(nil))
+	if encodedSum != v.expectedSum {
+		return ErrHashMismatch{
+			Function:   v.function,
+			Calculated: encodedSum,
+			Expected:   v.expectedSum,
+		}
+	}
+	return nil
+}
+
+// getHashVerifiers returns a hashVerifier for each of the hashes requested by
+// verify.
+func getHashVerifiers(verify types.Verification) ([]hashVerifier, error) {
+	verifiers := []hashVerifier{}
+	for _, h := range verify.AllHashes() {
+		function, sum, err := types.ParseHash(h)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		hashFunc, err := types.HashFunction(function)
+		if err != nil {
+			return nil, err
+		}
+		verifiers = append(verifiers, hashVerifier{
+			Hash:        hashFunc.New(),
+			function:    function,
+			expectedSum: sum,
+		})
+	}
+	return verifiers, nil
+}
+
+// Keyring is the set of public keys trusted to sign configs and file contents.
+type Keyring []crypto.PublicKey
 
-		var sum []byte
-		switch hashFunc {
-		case "sha512":
-			rawSum := sha512.Sum512(data)
-			sum = rawSum[:]
-		default:
-			return types.ErrHashUnrecognized
+// NewKeyring parses the given PEM encoded public keys.
+func NewKeyring(keys []string) (Keyring, error) {
+	var keyring Keyring
+	for _, key := range keys {
+		pub, err := types.ParseTrustedKey(key)
+		if err != nil {
+			return nil, err
 		}
+		keyring = append(keyring, pub)
+	}
+	return keyring, nil
+}
 
-		encodedSum := make([]byte, hex.EncodedLen(len(sum)))
-		hex.Encode(encodedSum, sum)
-		if string(encodedSum) != hashSum {
-			return ErrHashMismatch{
-				Calculated: string(encodedSum),
-				Expected:   hashSum,
+// verifyDigest checks that sig is a signature of the SHA-256 digest by one of
+// the keys in the keyring.
+func (k Keyring) verifyDigest(digest, sig []byte) error {
+	if len(k) == 0 {
+		return ErrNoTrustedKeys
+	}
+	if len(sig) == 0 {
+		return ErrSignatureMissing
+	}
+	for _, key := range k {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if verifyECDSA(key, digest, sig) {
+				return nil
 			}
 		}
 	}
+	return ErrSignatureInvalid
+}
 
-	return nil
+// ecdsaSignature is the ASN.1 encoding of an ECDSA signature.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// verifyECDSA checks that the ASN.1 encoded sig is a signature of digest by
+// key.
+func verifyECDSA(key *ecdsa.PublicKey, digest, sig []byte) bool {
+	var es ecdsaSignature
+	if rest, err := asn1.Unmarshal(sig, &es); err != nil || len(rest) != 0 {
+		return false
+	}
+	if es.R == nil || es.S == nil || es.R.Sign() <= 0 || es.S.Sign() <= 0 {
+		return false
+	}
+	return ecdsa.Verify(key, digest, es.R, es.S)
 }
 
-func GetHasher(verify types.Verification) (hash.Hash, error) {
-	if verify.Hash == nil {
+// FetchSignature fetches the detached signature requested by verify for the
+// resource at source. Unless the signature source is given, it is fetched
+// from the sibling URL with ".sig" appended. It returns nil if no signature
+// was requested or none was found; AssertValid and File.Verify reject the
+// resource in the latter case.
+func FetchSignature(l *log.Logger, c *resource.HttpClient, verify types.Verification, source string) ([]byte, error) {
+	if verify.Signature == nil {
 		return nil, nil
 	}
 
-	function, _, err := verify.HashParts()
+	sigSource := verify.Signature.Source
+	if sigSource == "" {
+		sigSource = source + ".sig"
+	}
+	u, err := url.Parse(sigSource)
 	if err != nil {
 		return nil, err
 	}
 
-	switch function {
-	case "sha512":
-		return sha512.New(), nil
-	default:
-		return nil, types.ErrHashUnrecognized
+	sig, err := resource.Fetch(l, c, context.Background(), *u)
+	if err == resource.ErrNotFound {
+		return nil, nil
+	}
+	return sig, err
+}
+
+// AssertValid checks data against every hash and the signature requested by
+// verify. The signature must have been made by one of the trusted keys.
+func AssertValid(verify types.Verification, data []byte, sig []byte, keys Keyring) error {
+	verifiers, err := getHashVerifiers(verify)
+	if err != nil {
+		return err
+	}
+	for _, v := range verifiers {
+		v.Write(data)
+		if err := v.verify(); err != nil {
+			return err
+		}
+	}
+
+	if verify.Signature != nil {
+		digest := sha256.Sum256(data)
+		return keys.verifyDigest(digest[:], sig)
 	}
+
+	return nil
 }
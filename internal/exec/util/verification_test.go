@@ -15,6 +15,11 @@
 package util
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
 	"reflect"
 	"testing"
 
@@ -25,6 +30,8 @@ func TestAssertValid(t *testing.T) {
 	type in struct {
 		verification types.Verification
 		data         []byte
+		signature    []byte
+		keys         Keyring
 	}
 	type out struct {
 		err error
@@ -32,6 +39,28 @@ func TestAssertValid(t *testing.T) {
 
 	stringDeref := func(s string) *string { return &s }
 
+	trusted, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrusted, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(key *ecdsa.PrivateKey, data string) []byte {
+		digest := sha256.Sum256([]byte(data))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	keys := Keyring{&trusted.PublicKey}
+
 	tests := []struct {
 		in  in
 		out out
@@ -65,14 +94,95 @@ func TestAssertValid(t *testing.T) {
 				data: []byte("hello"),
 			},
 			out: out{err: ErrHashMismatch{
+				Function:   "sha512",
 				Calculated: "9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043",
 				Expected:   "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 			}},
 		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha256-2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"),
+				},
+				data: []byte("hello"),
+			},
+			out: out{},
+		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hashes: []string{
+						"sha384-59e1748777448c69de6b800d7a33bbfb9ff1b463e44354c3553bcdb9c666fa90125a3c79f90397bdf5f6a13de828684f",
+						"sha512-9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043",
+					},
+				},
+				data: []byte("hello"),
+			},
+			out: out{},
+		},
+		{
+			in: in{
+				verification: types.Verification{
+					Hash: stringDeref("sha512-9b71d224bd62f3785d96d46ad3ea3d73319bfbc2890caadae2dff72519673ca72323c3d99ba5c11d7c7acc6e14b8c5da0c4663475c2e5c3adef46f73bcdec043"),
+					Hashes: []string{
+						"sha256-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+					},
+				},
+				data: []byte("hello"),
+			},
+			out: out{err: ErrHashMismatch{
+				Function:   "sha256",
+				Calculated: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+				Expected:   "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			}},
+		},
+		{
+			in: in{
+				verification: types.Verification{Signature: &types.Signature{}},
+				data:         []byte("hello"),
+				signature:    sign(trusted, "hello"),
+				keys:         keys,
+			},
+			out: out{},
+		},
+		{
+			in: in{
+				verification: types.Verification{Signature: &types.Signature{}},
+				data:         []byte("hello"),
+				keys:         keys,
+			},
+			out: out{err: ErrSignatureMissing},
+		},
+		{
+			in: in{
+				verification: types.Verification{Signature: &types.Signature{}},
+				data:         []byte("hello"),
+				signature:    sign(trusted, "goodbye"),
+				keys:         keys,
+			},
+			out: out{err: ErrSignatureInvalid},
+		},
+		{
+			in: in{
+				verification: types.Verification{Signature: &types.Signature{}},
+				data:         []byte("hello"),
+				signature:    sign(untrusted, "hello"),
+				keys:         keys,
+			},
+			out: out{err: ErrSignatureInvalid},
+		},
+		{
+			in: in{
+				verification: types.Verification{Signature: &types.Signature{}},
+				data:         []byte("hello"),
+				signature:    sign(trusted, "hello"),
+			},
+			out: out{err: ErrNoTrustedKeys},
+		},
 	}
 
 	for i, test := range tests {
-		err := AssertValid(test.in.verification, test.in.data)
+		err := AssertValid(test.in.verification, test.in.data, test.in.signature, test.in.keys)
 		if !reflect.DeepEqual(test.out.err, err) {
 			t.Errorf("#%d: bad err: want %+v, got %+v", i, test.out.err, err)
 		}
@@ -40,6 +40,13 @@ type Logger struct {
 	ops           LoggerOps
 	prefixStack   []string
 	opSequenceNum int
+	plan          *plan
+}
+
+// plan records the operations of a Logger in plan mode.
+type plan struct {
+	ops       []string
+	suspended int
 }
 
 // New creates a new logger.
@@ -116,6 +123,57 @@ func (l *Logger) PopPrefix() {
 	l.prefixStack = l.prefixStack[:len(l.prefixStack)-1]
 }
 
+// StartPlan puts the Logger into plan mode, in which LogOp and LogCmd record
+// and log their operations instead of performing them. Code which inspects
+// the system through logged operations should do so within Inspect.
+func (l *Logger) StartPlan() {
+	l.plan = &plan{}
+}
+
+// StopPlan takes the Logger out of plan mode and returns the operations which
+// were recorded, in order.
+func (l *Logger) StopPlan() []string {
+	if l.plan == nil {
+		return nil
+	}
+	ops := l.plan.ops
+	l.plan = nil
+	return ops
+}
+
+// Planning returns whether the Logger is in plan mode, i.e. whether logged
+// operations are being recorded rather than performed.
+func (l Logger) Planning() bool {
+	return l.plan != nil && l.plan.suspended == 0
+}
+
+// Inspect calls op with plan mode suspended, so that the logged operations of
+// op are performed even when planning. op must only read the state of the
+// system.
+func (l *Logger) Inspect(op func() error) error {
+	if l.plan == nil {
+		return op()
+	}
+	l.plan.suspended++
+	defer func() { l.plan.suspended-- }()
+	return op()
+}
+
+// LogSkip logs that an operation is skipped since the system is already in the
+// intended state. In plan mode it is also recorded, marked as skipped.
+func (l *Logger) LogSkip(format string, a ...interface{}) {
+	if l.Planning() {
+		l.plan.ops = append(l.plan.ops, l.sprintf("[skipped] %s", fmt.Sprintf(format, a...)))
+	}
+	l.Info(format, a...)
+}
+
+// logPlanned records and logs an operation which isn't performed in plan mode.
+func (l *Logger) logPlanned(format string, a ...interface{}) {
+	l.plan.ops = append(l.plan.ops, l.sprintf(format, a...))
+	l.Info(fmt.Sprintf("[planned]  %s", format), a...)
+}
+
 // quotedCmd returns a concatenated, quoted form of cmd's cmdline
 func quotedCmd(cmd *exec.Cmd) string {
 	if len(cmd.Args) == 0 {
@@ -132,7 +190,13 @@ func quotedCmd(cmd *exec.Cmd) string {
 
 // LogCmd runs and logs the supplied cmd as an operation with distinct start/finish/fail log messages uniformly combined with the supplied format string.
 // The exact command path and arguments being executed are also logged for debugging assistance.
+// In plan mode the command isn't run, and is recorded along with its arguments.
 func (l *Logger) LogCmd(cmd *exec.Cmd, format string, a ...interface{}) (int, error) {
+	if l.Planning() {
+		l.logPlanned("%s: %s", fmt.Sprintf(format, a...), quotedCmd(cmd))
+		return 0, nil
+	}
+
 	code := -1
 	f := func() error {
 		cmdLine := quotedCmd(cmd)
@@ -155,7 +219,13 @@ func (l *Logger) LogCmd(cmd *exec.Cmd, format string, a ...interface{}) (int, er
 }
 
 // LogOp calls and logs the supplied function as an operation with distinct start/finish/fail log messages uniformly combined with the supplied format string.
+// In plan mode the function isn't called, and the operation is only recorded.
 func (l *Logger) LogOp(op func() error, format string, a ...interface{}) error {
+	if l.Planning() {
+		l.logPlanned(format, a...)
+		return nil
+	}
+
 	l.opSequenceNum++
 	l.PushPrefix("op(%x)", l.opSequenceNum)
 	defer l.PopPrefix()
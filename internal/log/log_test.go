@@ -0,0 +1,56 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	l := Logger{ops: Stdout{}}
+	l.PushPrefix("stage")
+
+	performed := []string{}
+	op := func(name string) func() error {
+		return func() error {
+			performed = append(performed, name)
+			return nil
+		}
+	}
+
+	l.StartPlan()
+	l.LogOp(op("write"), "writing %q", "/a")
+	l.LogCmd(exec.Command("/bin/true", "-x"), "running %q", "true")
+	l.Inspect(func() error { return l.LogOp(op("inspect"), "inspecting %q", "/b") })
+	l.LogSkip("%q is up to date", "/c")
+	ops := l.StopPlan()
+	l.LogOp(op("after"), "writing %q", "/d")
+
+	expectedOps := []string{
+		`stage: writing "/a"`,
+		`stage: running "true": "/bin/true" "-x"`,
+		`stage: [skipped] "/c" is up to date`,
+	}
+	if !reflect.DeepEqual(expectedOps, ops) {
+		t.Errorf("bad ops: want %q, got %q", expectedOps, ops)
+	}
+
+	expectedPerformed := []string{"inspect", "after"}
+	if !reflect.DeepEqual(expectedPerformed, performed) {
+		t.Errorf("bad performed ops: want %q, got %q", expectedPerformed, performed)
+	}
+}
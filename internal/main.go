@@ -18,11 +18,14 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/coreos/ignition/internal/exec"
 	"github.com/coreos/ignition/internal/exec/stages"
 	_ "github.com/coreos/ignition/internal/exec/stages/disks"
 	_ "github.com/coreos/ignition/internal/exec/stages/files"
+	_ "github.com/coreos/ignition/internal/exec/stages/mount"
+	_ "github.com/coreos/ignition/internal/exec/stages/umount"
 	"github.com/coreos/ignition/internal/log"
 	"github.com/coreos/ignition/internal/oem"
 	"github.com/coreos/ignition/internal/version"
@@ -32,7 +35,11 @@ func main() {
 	flags := struct {
 		clearCache  bool
 		configCache string
+		image       string
+		imageDevice string
+		imageRoot   string
 		oem         oem.Name
+		plan        bool
 		root        string
 		stage       stages.Name
 		version     bool
@@ -40,7 +47,11 @@ func main() {
 
 	flag.BoolVar(&flags.clearCache, "clear-cache", false, "clear any cached config")
 	flag.StringVar(&flags.configCache, "config-cache", "/run/ignition.json", "where to cache the config")
+	flag.StringVar(&flags.image, "image", "", "raw disk image to apply the config to, instead of the stage")
+	flag.StringVar(&flags.imageDevice, "image-device", "/dev/sda", "device the image stands in for in the config")
+	flag.StringVar(&flags.imageRoot, "image-root", "", "partition of the image device holding the root filesystem")
 	flag.Var(&flags.oem, "oem", fmt.Sprintf("current oem. %v", oem.Names()))
+	flag.BoolVar(&flags.plan, "plan", false, "print the operations of the stage instead of performing them")
 	flag.StringVar(&flags.root, "root", "/", "root of the filesystem")
 	flag.Var(&flags.stage, "stage", fmt.Sprintf("execution stage. %v", stages.Names()))
 	flag.BoolVar(&flags.version, "version", false, "print the version and exit")
@@ -57,8 +68,29 @@ func main() {
 		os.Exit(2)
 	}
 
-	if flags.stage == "" {
-		fmt.Fprint(os.Stderr, "'--stage' must be provided\n")
+	if flags.image == "" && flags.stage == "" {
+		fmt.Fprint(os.Stderr, "'--stage' or '--image' must be provided\n")
+		os.Exit(2)
+	}
+
+	if flags.image != "" && flags.plan {
+		fmt.Fprint(os.Stderr, "'--plan' cannot be used with '--image'\n")
+		os.Exit(2)
+	}
+
+	if flags.plan && flags.stage == stages.FetchName {
+		fmt.Fprintf(os.Stderr, "'--plan' cannot be used with the %s stage\n", stages.FetchName)
+		os.Exit(2)
+	}
+
+	if flags.image != "" && flags.imageRoot == "" {
+		fmt.Fprint(os.Stderr, "'--image-root' must be provided with '--image'\n")
+		os.Exit(2)
+	}
+
+	root, err := filepath.Abs(flags.root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid '--root': %v\n", err)
 		os.Exit(2)
 	}
 
@@ -75,14 +107,29 @@ func main() {
 
 	oemConfig := oem.MustGet(flags.oem.String())
 	engine := exec.Engine{
-		Root:              flags.root,
+		Root:              root,
 		Logger:            &logger,
 		ConfigCache:       flags.configCache,
 		FetchFunc:         oemConfig.FetchFunc(),
+		FetchMetadataFunc: oemConfig.MetadataFunc(),
 		OemBaseConfig:     oemConfig.BaseConfig(),
 		DefaultUserConfig: oemConfig.DefaultUserConfig(),
 	}
 
+	if flags.image != "" {
+		if !engine.RunImage(flags.image, flags.imageDevice, flags.imageRoot) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if flags.plan {
+		if !engine.Plan(flags.stage.String()) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if !engine.Run(flags.stage.String()) {
 		os.Exit(1)
 	}
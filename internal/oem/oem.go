@@ -39,6 +39,7 @@ import (
 type Config struct {
 	name              string
 	fetch             providers.FuncFetchConfig
+	fetchMetadata     providers.FuncFetchMetadata
 	baseConfig        types.Config
 	defaultUserConfig types.Config
 }
@@ -51,6 +52,12 @@ func (c Config) FetchFunc() providers.FuncFetchConfig {
 	return c.fetch
 }
 
+// MetadataFunc returns the function used to fetch the platform metadata for
+// templating, or nil if the OEM does not provide any.
+func (c Config) MetadataFunc() providers.FuncFetchMetadata {
+	return c.fetchMetadata
+}
+
 func (c Config) BaseConfig() types.Config {
 	return c.baseConfig
 }
@@ -90,8 +97,9 @@ func init() {
 		fetch: noop.FetchConfig,
 	})
 	configs.Register(Config{
-		name:  "digitalocean",
-		fetch: digitalocean.FetchConfig,
+		name:          "digitalocean",
+		fetch:         digitalocean.FetchConfig,
+		fetchMetadata: digitalocean.FetchMetadata,
 		baseConfig: types.Config{
 			Systemd: types.Systemd{
 				Units: []types.Unit{{Enable: true, Name: "coreos-metadata-sshkeys@.service"}},
@@ -110,8 +118,9 @@ func init() {
 		defaultUserConfig: types.Config{Systemd: types.Systemd{Units: []types.Unit{userCloudInit("OpenStack", "ec2-compat")}}},
 	})
 	configs.Register(Config{
-		name:  "ec2",
-		fetch: ec2.FetchConfig,
+		name:          "ec2",
+		fetch:         ec2.FetchConfig,
+		fetchMetadata: ec2.FetchMetadata,
 		baseConfig: types.Config{
 			Systemd: types.Systemd{
 				Units: []types.Unit{
@@ -140,8 +149,9 @@ func init() {
 		fetch: noop.FetchConfig,
 	})
 	configs.Register(Config{
-		name:  "gce",
-		fetch: gce.FetchConfig,
+		name:          "gce",
+		fetch:         gce.FetchConfig,
+		fetchMetadata: gce.FetchMetadata,
 		baseConfig: types.Config{
 			Systemd: types.Systemd{
 				Units: []types.Unit{
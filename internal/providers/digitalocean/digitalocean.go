@@ -13,11 +13,13 @@
 // limitations under the License.
 
 // The digitalocean provider fetches a remote configuration from the
-// digitalocean user-data metadata service URL.
+// digitalocean user-data metadata service URL. It also exposes a subset of the
+// droplet metadata for templating.
 
 package digitalocean
 
 import (
+	"net/http"
 	"net/url"
 
 	"github.com/coreos/ignition/config/types"
@@ -35,8 +37,22 @@ var (
 		Host:   "169.254.169.254",
 		Path:   "metadata/v1/user-data",
 	}
+	metadataUrls = map[string]url.URL{
+		"droplet_id":   metadataUrl("id"),
+		"hostname":     metadataUrl("hostname"),
+		"private_ipv4": metadataUrl("interfaces/private/0/ipv4/address"),
+		"public_ipv4":  metadataUrl("interfaces/public/0/ipv4/address"),
+	}
 )
 
+func metadataUrl(name string) url.URL {
+	return url.URL{
+		Scheme: "http",
+		Host:   "169.254.169.254",
+		Path:   "metadata/v1/" + name,
+	}
+}
+
 func FetchConfig(logger *log.Logger, client *resource.HttpClient) (types.Config, report.Report, error) {
 	data, err := resource.FetchConfig(logger, client, context.Background(), userdataUrl)
 	if err != nil {
@@ -45,3 +61,7 @@ func FetchConfig(logger *log.Logger, client *resource.HttpClient) (types.Config,
 
 	return util.ParseConfig(logger, data)
 }
+
+func FetchMetadata(logger *log.Logger, client *resource.HttpClient) (map[string]string, error) {
+	return util.FetchMetadata(logger, client, http.Header{}, metadataUrls)
+}
@@ -13,11 +13,13 @@
 // limitations under the License.
 
 // The ec2 provider fetches a remote configuration from the ec2 user-data
-// metadata service URL.
+// metadata service URL. It also exposes a subset of the instance metadata for
+// templating.
 
 package ec2
 
 import (
+	"net/http"
 	"net/url"
 
 	"github.com/coreos/ignition/config/types"
@@ -35,8 +37,22 @@ var (
 		Host:   "169.254.169.254",
 		Path:   "2009-04-04/user-data",
 	}
+	metadataUrls = map[string]url.URL{
+		"instance_id": metadataUrl("instance-id"),
+		"local_ipv4":  metadataUrl("local-ipv4"),
+		"public_ipv4": metadataUrl("public-ipv4"),
+		"hostname":    metadataUrl("hostname"),
+	}
 )
 
+func metadataUrl(name string) url.URL {
+	return url.URL{
+		Scheme: "http",
+		Host:   "169.254.169.254",
+		Path:   "2009-04-04/meta-data/" + name,
+	}
+}
+
 func FetchConfig(logger *log.Logger, client *resource.HttpClient) (types.Config, report.Report, error) {
 	data, err := resource.FetchConfig(logger, client, context.Background(), userdataUrl)
 	if err != nil {
@@ -45,3 +61,7 @@ func FetchConfig(logger *log.Logger, client *resource.HttpClient) (types.Config,
 
 	return util.ParseConfig(logger, data)
 }
+
+func FetchMetadata(logger *log.Logger, client *resource.HttpClient) (map[string]string, error) {
+	return util.FetchMetadata(logger, client, http.Header{}, metadataUrls)
+}
@@ -13,7 +13,8 @@
 // limitations under the License.
 
 // The gce provider fetches a remote configuration from the gce user-data
-// metadata service URL.
+// metadata service URL. It also exposes a subset of the instance metadata for
+// templating.
 
 package gce
 
@@ -37,8 +38,22 @@ var (
 		Path:   "computeMetadata/v1/instance/attributes/user-data",
 	}
 	metadataHeader = http.Header{"Metadata-Flavor": []string{"Google"}}
+	metadataUrls   = map[string]url.URL{
+		"instance_id": metadataUrl("id"),
+		"hostname":    metadataUrl("hostname"),
+		"local_ipv4":  metadataUrl("network-interfaces/0/ip"),
+		"public_ipv4": metadataUrl("network-interfaces/0/access-configs/0/external-ip"),
+	}
 )
 
+func metadataUrl(name string) url.URL {
+	return url.URL{
+		Scheme: "http",
+		Host:   "metadata.google.internal",
+		Path:   "computeMetadata/v1/instance/" + name,
+	}
+}
+
 func FetchConfig(logger *log.Logger, client *resource.HttpClient) (types.Config, report.Report, error) {
 	data, err := resource.FetchConfigWithHeader(logger, client, context.Background(), userdataUrl, metadataHeader)
 	if err != nil {
@@ -47,3 +62,7 @@ func FetchConfig(logger *log.Logger, client *resource.HttpClient) (types.Config,
 
 	return util.ParseConfig(logger, data)
 }
+
+func FetchMetadata(logger *log.Logger, client *resource.HttpClient) (map[string]string, error) {
+	return util.FetchMetadata(logger, client, metadataHeader, metadataUrls)
+}
@@ -28,3 +28,7 @@ var (
 )
 
 type FuncFetchConfig func(logger *log.Logger, client *resource.HttpClient) (types.Config, report.Report, error)
+
+// FuncFetchMetadata returns the platform metadata which may be referenced by
+// templated file and unit contents, keyed by variable name.
+type FuncFetchMetadata func(logger *log.Logger, client *resource.HttpClient) (map[string]string, error)
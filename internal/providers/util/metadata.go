@@ -0,0 +1,46 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/coreos/ignition/internal/log"
+	"github.com/coreos/ignition/internal/resource"
+
+	"golang.org/x/net/context"
+)
+
+// FetchMetadata fetches each of the given metadata URLs and returns the
+// results keyed by variable name. Values the metadata service does not have
+// (e.g. a public IP on an instance without one) are left out of the map so
+// that templates referencing them fail to render.
+func FetchMetadata(logger *log.Logger, client *resource.HttpClient, header http.Header, urls map[string]url.URL) (map[string]string, error) {
+	metadata := map[string]string{}
+	for name, u := range urls {
+		data, err := resource.FetchWithHeader(logger, client, context.Background(), u, header)
+		switch err {
+		case nil:
+			metadata[name] = strings.TrimSpace(string(data))
+		case resource.ErrNotFound:
+			logger.Debug("metadata %q not available", name)
+		default:
+			return nil, err
+		}
+	}
+	return metadata, nil
+}
@@ -16,11 +16,14 @@ package resource
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/types"
 	"github.com/coreos/ignition/internal/log"
 	"github.com/coreos/ignition/internal/version"
 
@@ -32,25 +35,52 @@ const (
 	maxAttempts    = 15
 	initialBackoff = 100 * time.Millisecond
 	maxBackoff     = 5 * time.Second
+
+	defaultHttpResponseHeaderTimeout = config.DefaultHTTPResponseHeadersTimeout
+	defaultHttpTotalTimeout          = config.DefaultHTTPTotalTimeout
 )
 
 var (
 	ErrAttemptsExhausted = errors.New("unable to fetch resource (no more attempts available)")
 )
 
+// ErrTimeout is returned when fetching a resource exceeds one of the limits
+// configured in ignition.timeouts. Limit names the limit that was hit.
+type ErrTimeout struct {
+	Limit   string
+	Timeout time.Duration
+}
+
+func (e ErrTimeout) Error() string {
+	return fmt.Sprintf("%s timeout of %s exceeded", e.Limit, e.Timeout)
+}
+
 // HttpClient is a simple wrapper around the Go HTTP client that standardizes
 // the process and logging of fetching payloads.
 type HttpClient struct {
 	client *http.Client
 	logger *log.Logger
+
+	responseHeaderTimeout time.Duration
+	totalTimeout          time.Duration
 }
 
-// NewHttpClient creates a new client with the given logger.
-func NewHttpClient(logger *log.Logger) HttpClient {
+// NewHttpClient creates a new client with the given logger and timeouts. Nil
+// timeouts fall back to their defaults, while a timeout of zero means no limit.
+func NewHttpClient(logger *log.Logger, timeouts types.Timeouts) HttpClient {
+	responseHeader := defaultHttpResponseHeaderTimeout
+	total := defaultHttpTotalTimeout
+	if timeouts.HTTPResponseHeaders != nil {
+		responseHeader = *timeouts.HTTPResponseHeaders
+	}
+	if timeouts.HTTPTotal != nil {
+		total = *timeouts.HTTPTotal
+	}
+
 	return HttpClient{
 		client: &http.Client{
 			Transport: &http.Transport{
-				ResponseHeaderTimeout: 10 * time.Second,
+				ResponseHeaderTimeout: time.Duration(responseHeader) * time.Second,
 				Dial: (&net.Dialer{
 					Timeout:   30 * time.Second,
 					KeepAlive: 30 * time.Second,
@@ -59,12 +89,39 @@ func NewHttpClient(logger *log.Logger) HttpClient {
 			},
 		},
 		logger: logger,
+
+		responseHeaderTimeout: time.Duration(responseHeader) * time.Second,
+		totalTimeout:          time.Duration(total) * time.Second,
 	}
 }
 
+// deadlineBody wraps a response body so that the context bounding the request
+// is released once the body is closed, and so that reads cut short by the
+// httpTotal deadline report which limit was hit.
+type deadlineBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+func (b deadlineBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.ctx.Err() == context.DeadlineExceeded {
+		err = ErrTimeout{Limit: "httpTotal", Timeout: b.timeout}
+	}
+	return n, err
+}
+
+func (b deadlineBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
 // getReaderWithHeader performs an HTTP GET on the provided URL with the provided request header
 // and returns the response body Reader, HTTP status code, and error (if any). By
-// default, User-Agent is added to the header but this can be overridden.
+// default, User-Agent is added to the header but this can be overridden. If an
+// httpTotal timeout is set, it bounds every attempt as well as reading the body.
 func (c HttpClient) getReaderWithHeader(ctx context.Context, url string, header http.Header) (io.ReadCloser, int, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -80,6 +137,12 @@ func (c HttpClient) getReaderWithHeader(ctx context.Context, url string, header
 		}
 	}
 
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	if c.totalTimeout != 0 {
+		ctx, cancel = context.WithTimeout(parent, c.totalTimeout)
+	}
+
 	duration := initialBackoff
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		c.logger.Debug("GET %s: attempt #%d", url, attempt)
@@ -88,9 +151,16 @@ func (c HttpClient) getReaderWithHeader(ctx context.Context, url string, header
 		if err == nil {
 			c.logger.Debug("GET result: %s", http.StatusText(resp.StatusCode))
 			if resp.StatusCode < 500 {
-				return resp.Body, resp.StatusCode, nil
+				return deadlineBody{
+					ReadCloser: resp.Body,
+					ctx:        ctx,
+					cancel:     cancel,
+					timeout:    c.totalTimeout,
+				}, resp.StatusCode, nil
 			}
 			resp.Body.Close()
+		} else if nerr, ok := err.(net.Error); ok && nerr.Timeout() && ctx.Err() == nil {
+			c.logger.Info("GET %s: %v", url, ErrTimeout{Limit: "httpResponseHeaders", Timeout: c.responseHeaderTimeout})
 		} else {
 			c.logger.Debug("GET error: %v", err)
 		}
@@ -103,9 +173,16 @@ func (c HttpClient) getReaderWithHeader(ctx context.Context, url string, header
 		select {
 		case <-time.After(duration):
 		case <-ctx.Done():
+			cancel()
+			if parent.Err() == nil && ctx.Err() == context.DeadlineExceeded {
+				err := ErrTimeout{Limit: "httpTotal", Timeout: c.totalTimeout}
+				c.logger.Err("GET %s: %v", url, err)
+				return nil, 0, err
+			}
 			return nil, 0, ctx.Err()
 		}
 	}
 
+	cancel()
 	return nil, 0, ErrAttemptsExhausted
 }
@@ -0,0 +1,57 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/ignition/config/types"
+	"github.com/coreos/ignition/internal/log"
+
+	"golang.org/x/net/context"
+)
+
+func TestGetReaderWithHeaderTotalTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	total := 1
+	logger := log.New()
+	client := NewHttpClient(&logger, types.Timeouts{HTTPTotal: &total})
+
+	_, _, err := client.getReaderWithHeader(context.Background(), server.URL, http.Header{})
+	want := ErrTimeout{Limit: "httpTotal", Timeout: time.Second}
+	if !reflect.DeepEqual(want, err) {
+		t.Errorf("bad error: want %v, got %v", want, err)
+	}
+}
+
+func TestNewHttpClientDefaults(t *testing.T) {
+	logger := log.New()
+	client := NewHttpClient(&logger, types.Timeouts{})
+
+	if client.responseHeaderTimeout != 10*time.Second {
+		t.Errorf("bad response header timeout: want %v, got %v", 10*time.Second, client.responseHeaderTimeout)
+	}
+	if client.totalTimeout != 0 {
+		t.Errorf("bad total timeout: want %v, got %v", time.Duration(0), client.totalTimeout)
+	}
+}
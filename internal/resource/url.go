@@ -60,7 +60,7 @@ func FetchConfig(l *log.Logger, c *HttpClient, ctx context.Context, u url.URL) (
 func FetchConfigWithHeader(l *log.Logger, c *HttpClient, ctx context.Context, u url.URL, h http.Header) ([]byte, error) {
 	header := http.Header{
 		"Accept-Encoding": []string{"identity"},
-		"Accept":          []string{"application/vnd.coreos.ignition+json; version=2.0.0, application/vnd.coreos.ignition+json; version=1; q=0.5, */*; q=0.1"},
+		"Accept":          []string{"application/vnd.coreos.ignition+json; version=2.1.0, application/vnd.coreos.ignition+json; version=2.0.0; q=0.9, application/vnd.coreos.ignition+json; version=1; q=0.5, */*; q=0.1"},
 	}
 	for key, values := range h {
 		header.Del(key)
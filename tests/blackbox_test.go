@@ -555,6 +555,7 @@ func outer(t *testing.T, test Test) {
 	}
 	writeIgnitionConfig(t, config)
 	root := getRootLocation(t, test.in)
+	runIgnition(t, "fetch", root)
 	runIgnition(t, "disks", root)
 	runIgnition(t, "files", root)
 
@@ -626,9 +627,11 @@ func removeMountFolders(t *testing.T, partitions []*Partition) {
 }
 
 func runIgnition(t *testing.T, stage string, root string) {
-	out, err := exec.Command(
-		"ignition", "-clear-cache", "-oem",
-		"file", "-stage", stage, "-root", root).CombinedOutput()
+	args := []string{"-oem", "file", "-stage", stage, "-root", root}
+	if stage == "fetch" {
+		args = append([]string{"-clear-cache"}, args...)
+	}
+	out, err := exec.Command("ignition", args...).CombinedOutput()
 	debugInfo, derr := ioutil.ReadFile("/var/log/syslog")
 	if derr == nil {
 		debugOut := []string{}
@@ -0,0 +1,140 @@
+// Copyright 2017 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ignition-validate parses Ignition configs and prints the resulting reports.
+// It exits with exitErrors if any config is invalid, with exitWarnings if the
+// configs are valid but produced warnings or deprecations, and with exitValid
+// otherwise.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/coreos/ignition/config"
+	"github.com/coreos/ignition/config/validate/report"
+	"github.com/coreos/ignition/internal/version"
+)
+
+const (
+	exitValid    = 0
+	exitErrors   = 1
+	exitUsage    = 2
+	exitWarnings = 3
+)
+
+// result is the report of a single config, as printed in the JSON output.
+type result struct {
+	File    string         `json:"file"`
+	Entries []report.Entry `json:"entries"`
+}
+
+func main() {
+	flags := struct {
+		json    bool
+		version bool
+	}{}
+
+	flag.BoolVar(&flags.json, "json", false, "print the reports as JSON")
+	flag.BoolVar(&flags.version, "version", false, "print the version and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] [config...]\n\n", os.Args[0])
+		fmt.Fprint(os.Stderr, "Validates the given configs, or the config on stdin if none (or \"-\") is given.\n")
+		fmt.Fprint(os.Stderr, "Files ending in .yaml or .yml are parsed as YAML.\n\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExit status is %d if all configs are valid, %d if any config is invalid,\n", exitValid, exitErrors)
+		fmt.Fprintf(os.Stderr, "and %d if the configs are valid but have warnings or deprecations.\n", exitWarnings)
+	}
+
+	flag.Parse()
+
+	if flags.version {
+		fmt.Printf("%s\n", version.String)
+		return
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var results []result
+	status := exitValid
+	for _, file := range files {
+		r := validate(file)
+		results = append(results, result{File: file, Entries: r.Entries})
+
+		if r.IsFatal() {
+			status = exitErrors
+		} else if hasWarnings(r) && status == exitValid {
+			status = exitWarnings
+		}
+	}
+
+	if flags.json {
+		if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode reports: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	} else {
+		for _, res := range results {
+			for _, entry := range res.Entries {
+				fmt.Printf("%s: %s\n", res.File, entry)
+			}
+		}
+	}
+
+	os.Exit(status)
+}
+
+// validate reads and parses the config in file ("-" for stdin) and returns
+// the resulting report. Failures which are not already part of the report
+// (e.g. the file being unreadable or empty) are added to it as errors.
+func validate(file string) report.Report {
+	var rawConfig []byte
+	var err error
+	if file == "-" {
+		rawConfig, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		rawConfig, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return report.ReportFromError(err, report.EntryError)
+	}
+
+	parse := config.Parse
+	if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+		parse = config.ParseYAML
+	}
+
+	_, r, err := parse(rawConfig)
+	if err != nil && !r.IsFatal() {
+		r.Merge(report.ReportFromError(err, report.EntryError))
+	}
+	r.Sort()
+	return r
+}
+
+func hasWarnings(r report.Report) bool {
+	for _, entry := range r.Entries {
+		if entry.Kind == report.EntryWarning || entry.Kind == report.EntryDeprecated {
+			return true
+		}
+	}
+	return false
+}